@@ -0,0 +1,288 @@
+package go_cache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// arena条目的头部布局，所有整数均为小端序：
+//
+//	[0:4]   条目总长度
+//	[4:8]   键的长度
+//	[8:12]  值的长度
+//	[12:16] 标签编码后的长度
+//	[16:24] 过期时间的UnixNano，0表示永不过期
+//	[24]    标志位
+//	[25:33] 滑动过期时长的纳秒数，0表示不滑动
+//
+// 头部之后依次是键、值和标签，每个标签以4字节长度开头
+const (
+	arenaHeaderSize = 33
+	arenaFlagLive   = 1
+)
+
+// arenaStore 将缓存项保存在预先分配的环形字节数组中，索引为map[uint64]uint32，
+// 二者都不包含指针，GC不需要扫描其中的条目。
+// 写入总是追加到环尾，覆盖或删除只将旧条目标记为失效；空间不足时从环头开始移除最旧的条目（FIFO）。
+// 索引以键的64位哈希为键，哈希冲突时后写入的键会替换先写入的键
+type arenaStore struct {
+	buf        []byte
+	index      map[uint64]uint32 // 键的哈希到条目位置
+	head, tail int               // 最旧条目的位置和下一次写入的位置
+	wrap       int               // 写入绕回环头后，环尾最后一个条目的结束位置，未绕回时为-1
+	entries    int               // 环中的条目数，包括已失效的
+	live       int               // 有效的条目数
+	maxEntries int
+}
+
+func newArenaStore(capacity int64, maxEntries int) *arenaStore {
+	return &arenaStore{
+		buf:        make([]byte, capacity),
+		index:      make(map[uint64]uint32),
+		wrap:       -1,
+		maxEntries: maxEntries,
+	}
+}
+
+// arenaHash 计算键的64位FNV-1a哈希
+func arenaHash(key string) uint64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= 1099511628211
+	}
+	return h
+}
+
+func (a *arenaStore) get(key string) (*cacheItem, bool) {
+	off, ok := a.index[arenaHash(key)]
+	if !ok || a.keyAt(int(off)) != key {
+		return nil, false
+	}
+	return a.decode(int(off)), true
+}
+
+func (a *arenaStore) put(item *cacheItem) (*cacheItem, []*cacheItem, error) {
+	tagsLen := 0
+	for _, tag := range item.tags {
+		tagsLen += 4 + len(tag)
+	}
+	n := arenaHeaderSize + len(item.key) + len(item.value) + tagsLen
+	if n > len(a.buf) {
+		return nil, nil, fmt.Errorf("%w: entry of %d bytes exceeds arena capacity %d", ErrInvalidParameter, n, len(a.buf))
+	}
+
+	var old *cacheItem
+	var evicted []*cacheItem
+	h := arenaHash(item.key)
+	if off, ok := a.index[h]; ok {
+		prev := a.decode(int(off))
+		if prev.key == item.key {
+			old = prev
+		} else {
+			// 哈希冲突，先写入的键被替换
+			evicted = append(evicted, prev)
+		}
+		a.kill(int(off), h)
+	}
+	for a.maxEntries > 0 && a.live >= a.maxEntries {
+		if e, ok := a.popHead(); ok {
+			evicted = append(evicted, e)
+		}
+	}
+
+	off, popped := a.alloc(n)
+	evicted = append(evicted, popped...)
+	a.encode(off, n, item, tagsLen)
+	a.index[h] = uint32(off)
+	a.entries++
+	a.live++
+	return old, evicted, nil
+}
+
+func (a *arenaStore) remove(key string) (*cacheItem, bool) {
+	h := arenaHash(key)
+	off, ok := a.index[h]
+	if !ok || a.keyAt(int(off)) != key {
+		return nil, false
+	}
+	item := a.decode(int(off))
+	a.kill(int(off), h)
+	return item, true
+}
+
+func (a *arenaStore) setExpiration(item *cacheItem, expiration time.Time) {
+	item.expiration = expiration
+	off, ok := a.index[arenaHash(item.key)]
+	if !ok || a.keyAt(int(off)) != item.key {
+		return
+	}
+	binary.LittleEndian.PutUint64(a.buf[int(off)+16:], uint64(unixNano(expiration)))
+}
+
+// popExpired 从环头开始移除已失效和已过期的条目，遇到未过期的条目即停止，
+// 因此排在未过期条目之后的过期条目要等到环头推进时才释放空间
+func (a *arenaStore) popExpired(now time.Time, limit int) ([]*cacheItem, bool) {
+	var removed []*cacheItem
+	for n := 0; a.entries > 0; n++ {
+		live := a.buf[a.head+24]&arenaFlagLive != 0
+		if live && !a.expiredAt(a.head, now) {
+			return removed, false
+		}
+		if n == limit {
+			return removed, true
+		}
+		if e, ok := a.popHead(); ok {
+			removed = append(removed, e)
+		}
+	}
+	return removed, false
+}
+
+func (a *arenaStore) forEach(fn func(item *cacheItem)) {
+	pos := a.head
+	for i := 0; i < a.entries; i++ {
+		if a.buf[pos+24]&arenaFlagLive != 0 {
+			fn(a.decode(pos))
+		}
+		pos += int(binary.LittleEndian.Uint32(a.buf[pos:]))
+		if pos == a.wrap {
+			pos = 0
+		}
+	}
+}
+
+func (a *arenaStore) len() int {
+	return a.live
+}
+
+func (a *arenaStore) reset() {
+	a.index = make(map[uint64]uint32)
+	a.head, a.tail, a.wrap = 0, 0, -1
+	a.entries, a.live = 0, 0
+}
+
+// alloc 在环尾分配n字节，空间不足时移除环头的条目，返回分配的位置和被移除的有效条目
+func (a *arenaStore) alloc(n int) (int, []*cacheItem) {
+	var evicted []*cacheItem
+	for {
+		if a.entries == 0 {
+			a.head, a.tail, a.wrap = 0, 0, -1
+		}
+		if a.wrap < 0 {
+			if a.tail+n <= len(a.buf) {
+				off := a.tail
+				a.tail += n
+				return off, evicted
+			}
+			// 环尾空间不足，绕回环头
+			if n <= a.head {
+				a.wrap = a.tail
+				a.tail = n
+				return 0, evicted
+			}
+		} else if a.tail+n <= a.head {
+			off := a.tail
+			a.tail += n
+			return off, evicted
+		}
+		if e, ok := a.popHead(); ok {
+			evicted = append(evicted, e)
+		}
+	}
+}
+
+// popHead 移除环头的条目，条目有效时返回该条目
+func (a *arenaStore) popHead() (*cacheItem, bool) {
+	if a.entries == 0 {
+		return nil, false
+	}
+	off := a.head
+	var item *cacheItem
+	if a.buf[off+24]&arenaFlagLive != 0 {
+		item = a.decode(off)
+		a.kill(off, arenaHash(item.key))
+	}
+	a.head += int(binary.LittleEndian.Uint32(a.buf[off:]))
+	a.entries--
+	if a.head == a.wrap {
+		a.head, a.wrap = 0, -1
+	}
+	if a.entries == 0 {
+		a.head, a.tail, a.wrap = 0, 0, -1
+	}
+	return item, item != nil
+}
+
+// kill 将条目标记为失效并移出索引
+func (a *arenaStore) kill(off int, h uint64) {
+	a.buf[off+24] &^= arenaFlagLive
+	delete(a.index, h)
+	a.live--
+}
+
+func (a *arenaStore) keyAt(off int) string {
+	keyLen := int(binary.LittleEndian.Uint32(a.buf[off+4:]))
+	start := off + arenaHeaderSize
+	return string(a.buf[start : start+keyLen])
+}
+
+func (a *arenaStore) expiredAt(off int, now time.Time) bool {
+	exp := int64(binary.LittleEndian.Uint64(a.buf[off+16:]))
+	return exp != 0 && now.UnixNano() > exp
+}
+
+func (a *arenaStore) encode(off, n int, item *cacheItem, tagsLen int) {
+	b := a.buf[off : off+n]
+	binary.LittleEndian.PutUint32(b[0:], uint32(n))
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(item.key)))
+	binary.LittleEndian.PutUint32(b[8:], uint32(len(item.value)))
+	binary.LittleEndian.PutUint32(b[12:], uint32(tagsLen))
+	binary.LittleEndian.PutUint64(b[16:], uint64(unixNano(item.expiration)))
+	b[24] = arenaFlagLive
+	binary.LittleEndian.PutUint64(b[25:], uint64(item.sliding))
+
+	pos := arenaHeaderSize
+	pos += copy(b[pos:], item.key)
+	pos += copy(b[pos:], item.value)
+	for _, tag := range item.tags {
+		binary.LittleEndian.PutUint32(b[pos:], uint32(len(tag)))
+		pos += 4
+		pos += copy(b[pos:], tag)
+	}
+}
+
+func (a *arenaStore) decode(off int) *cacheItem {
+	b := a.buf[off:]
+	keyLen := int(binary.LittleEndian.Uint32(b[4:]))
+	valueLen := int(binary.LittleEndian.Uint32(b[8:]))
+	tagsLen := int(binary.LittleEndian.Uint32(b[12:]))
+	exp := int64(binary.LittleEndian.Uint64(b[16:]))
+
+	pos := arenaHeaderSize
+	item := &cacheItem{
+		key:     string(b[pos : pos+keyLen]),
+		value:   string(b[pos+keyLen : pos+keyLen+valueLen]),
+		sliding: time.Duration(binary.LittleEndian.Uint64(b[25:])),
+	}
+	if exp != 0 {
+		item.expiration = time.Unix(0, exp)
+	}
+	pos += keyLen + valueLen
+	for end := pos + tagsLen; pos < end; {
+		tagLen := int(binary.LittleEndian.Uint32(b[pos:]))
+		pos += 4
+		item.tags = append(item.tags, string(b[pos:pos+tagLen]))
+		pos += tagLen
+	}
+	return item
+}
+
+// unixNano 返回过期时间的UnixNano，零值表示永不过期
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
@@ -0,0 +1,97 @@
+package go_cache
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// encodeItems 序列化批量写入的值，序列化失败的键记录到errs中并被跳过
+func encodeItems(codec Codec, items map[string]interface{}, errs map[string]error) map[string]string {
+	encoded := make(map[string]string, len(items))
+	for key, value := range items {
+		str, err := encodeValue(codec, value)
+		if err != nil {
+			errs[key] = err
+			continue
+		}
+		encoded[key] = str
+	}
+	return encoded
+}
+
+// batchCacheOf 返回cache的批量操作实现，不支持批量操作的缓存逐个键执行
+func batchCacheOf(cache Cache) BatchCache {
+	if c, ok := cache.(BatchCache); ok {
+		return c
+	}
+	return &batchAdapter{cache: cache}
+}
+
+// batchAdapter 通过逐个键调用Cache实现BatchCache
+type batchAdapter struct {
+	cache Cache
+}
+
+func (a *batchAdapter) GetMulti(keys []string) (map[string]string, error) {
+	values := make(map[string]string, len(keys))
+	errs := make(map[string]error)
+	for _, key := range keys {
+		value, err := a.cache.Get(key)
+		if err != nil {
+			if !errors.Is(err, ErrKeyNotFound) {
+				errs[key] = err
+			}
+			continue
+		}
+		values[key] = value
+	}
+	return values, newBatchError(errs)
+}
+
+func (a *batchAdapter) SetMulti(items map[string]interface{}, expiration time.Duration) error {
+	errs := make(map[string]error)
+	for key, value := range items {
+		if err := a.cache.Set(key, value, expiration); err != nil {
+			errs[key] = err
+		}
+	}
+	return newBatchError(errs)
+}
+
+func (a *batchAdapter) DeleteMulti(keys []string) error {
+	errs := make(map[string]error)
+	for _, key := range keys {
+		if err := a.cache.Delete(key); err != nil {
+			errs[key] = err
+		}
+	}
+	return newBatchError(errs)
+}
+
+// parallelEach 以有限的并发度对每个键执行fn，返回失败键的错误
+func parallelEach(keys []string, concurrency int, fn func(key string) error) map[string]error {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs = make(map[string]error)
+		sem  = make(chan struct{}, concurrency)
+	)
+	for _, key := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(key); err != nil {
+				mu.Lock()
+				errs[key] = err
+				mu.Unlock()
+			}
+		}(key)
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,93 @@
+package go_cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBatchCache(t *testing.T) {
+	defer Init()()
+	fileCache, err := NewFileCache(testFilePath)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	caches := map[string]BatchCache{
+		"redis":   redisServer,
+		"memory":  NewMemoryCache(),
+		"arena":   newArenaTestCache(),
+		"file":    fileCache,
+		"disklog": newDiskLogTestCache(t, nil),
+		"multi":   NewMultiCache(NewMemoryCache(), fileCache),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			items := map[string]interface{}{
+				"batch_1": "v1",
+				"batch_2": 2,
+				"batch_3": map[string]string{"k": "v"},
+			}
+			if err := cache.SetMulti(items, 5*time.Second); err != nil {
+				t.Fatalf("批量设置失败: %v", err)
+			}
+
+			values, err := cache.GetMulti([]string{"batch_1", "batch_2", "batch_3", "batch_missing"})
+			if err != nil {
+				t.Fatalf("批量获取失败: %v", err)
+			}
+			want := map[string]string{"batch_1": "v1", "batch_2": "2", "batch_3": `{"k":"v"}`}
+			if len(values) != len(want) {
+				t.Errorf("期望获取 %d 个键, 实际 %d 个: %v", len(want), len(values), values)
+			}
+			for key, value := range want {
+				if values[key] != value {
+					t.Errorf("键 %s 期望值 %s, 实际值 %s", key, value, values[key])
+				}
+			}
+
+			if err := cache.DeleteMulti([]string{"batch_1", "batch_2", "batch_3"}); err != nil {
+				t.Fatalf("批量删除失败: %v", err)
+			}
+			values, _ = cache.GetMulti([]string{"batch_1", "batch_2", "batch_3"})
+			if len(values) != 0 {
+				t.Errorf("期望键已被删除, 实际: %v", values)
+			}
+
+			// 序列化失败的键单独报告，其余键照常写入
+			err = cache.SetMulti(map[string]interface{}{"batch_ok": "ok", "batch_bad": make(chan int)}, 5*time.Second)
+			var batchErr *BatchError
+			if !errors.As(err, &batchErr) || len(batchErr.Errors) != 1 || !errors.Is(batchErr.Errors["batch_bad"], ErrSerialization) {
+				t.Errorf("期望batch_bad序列化失败, 实际: %v", err)
+			}
+			values, _ = cache.GetMulti([]string{"batch_ok", "batch_bad"})
+			if len(values) != 1 || values["batch_ok"] != "ok" {
+				t.Errorf("期望只写入batch_ok, 实际: %v", values)
+			}
+			_ = cache.DeleteMulti([]string{"batch_ok"})
+		})
+	}
+}
+
+func TestMultiCache_GetMultiBackfill(t *testing.T) {
+	defer Init()()
+	memoryCache := NewMemoryCache()
+	fileCache, _ := NewFileCache(testFilePath)
+	cache := NewMultiCache(memoryCache, fileCache)
+	defer cache.Close()
+
+	_ = memoryCache.Set("k1", "memory", 0)
+	_ = fileCache.Set("k1", "file", 0)
+	_ = fileCache.Set("k2", "file", 0)
+
+	values, err := cache.GetMulti([]string{"k1", "k2", "k3"})
+	if err != nil {
+		t.Fatalf("批量获取失败: %v", err)
+	}
+	if values["k1"] != "memory" || values["k2"] != "file" || len(values) != 2 {
+		t.Errorf("期望优先使用上层缓存的值, 实际: %v", values)
+	}
+	if got, _ := memoryCache.Get("k2"); got != "file" {
+		t.Errorf("期望k2回填到内存缓存, 实际: %s", got)
+	}
+}
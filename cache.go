@@ -3,6 +3,9 @@ package go_cache
 import (
 	"context"
 	"errors"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -11,47 +14,211 @@ import (
 // RedisCache 实现了基于Redis的缓存库
 type RedisCache struct {
 	client    *redis.Client
-	ctx       context.Context
+	ctx       context.Context // 不带context的方法使用的默认ctx
 	prefixKey string
+	codec     Codec
+}
+
+// RedisCacheOptions Redis缓存的可选配置
+type RedisCacheOptions struct {
+	// Codec 非字符串值的序列化方式，默认为JSONCodec
+	Codec Codec
 }
 
 // NewRedisCache 创建一个新的Redis缓存实例
 func NewRedisCache(addr string, password string, db int, PrefixKey string) *RedisCache {
+	return NewRedisCacheWithOptions(addr, password, db, PrefixKey, RedisCacheOptions{})
+}
+
+// NewRedisCacheWithOptions 使用指定配置创建一个新的Redis缓存实例
+func NewRedisCacheWithOptions(addr string, password string, db int, PrefixKey string, opts RedisCacheOptions) *RedisCache {
 	client := redis.NewClient(&redis.Options{
 		Addr:     addr,
 		Password: password,
 		DB:       db,
 	})
 
+	if opts.Codec == nil {
+		opts.Codec = JSONCodec{}
+	}
 	return &RedisCache{
 		client:    client,
 		ctx:       context.Background(),
 		prefixKey: PrefixKey,
+		codec:     opts.Codec,
 	}
 }
 
+// encode 序列化值并转义，写入普通值的方法都通过它序列化
+func (r *RedisCache) encode(value interface{}) (string, error) {
+	str, err := encodeValue(r.codec, value)
+	if err != nil {
+		return "", err
+	}
+	return escapeRedisValue(str), nil
+}
+
 // Set 将键值对存储到缓存中，并设置过期时间
 func (r *RedisCache) Set(key string, value interface{}, expiration time.Duration) error {
-	return r.client.Set(r.ctx, r.prefixKey+key, ToString(value), expiration).Err()
+	return r.SetCtx(r.ctx, key, value, expiration)
+}
+
+// SetCtx 将键值对存储到缓存中，并设置过期时间
+func (r *RedisCache) SetCtx(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	str, err := r.encode(value)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.prefixKey+key, str, expiration).Err()
 }
 
 // Get 从缓存中获取指定键的值
 func (r *RedisCache) Get(key string) (string, error) {
-	val, err := r.client.Get(r.ctx, r.prefixKey+key).Result()
+	return r.GetCtx(r.ctx, key)
+}
+
+// GetCtx 从缓存中获取指定键的值，普通的键只需一条GET；滑动过期的键命中时再延长过期时间
+func (r *RedisCache) GetCtx(ctx context.Context, key string) (string, error) {
+	raw, err := r.client.Get(ctx, r.prefixKey+key).Result()
 	if errors.Is(err, redis.Nil) {
 		return "", ErrKeyNotFound
 	}
-	return val, err
+	if err != nil {
+		return "", err
+	}
+	value, sliding := decodeRedisValue(raw)
+	if sliding > 0 {
+		if err := r.touchSliding(ctx, []string{key}, []time.Duration{sliding}); err != nil {
+			return "", err
+		}
+	}
+	return value, nil
+}
+
+// 以redisFlag开头的值带有本库的标记，其他值原样保存：
+//
+//	redisSlidingMark + 滑动毫秒数 + ":" + 值   滑动过期的值
+//	redisEscapeMark + 值                      本身以redisFlag开头的普通值，转义后保存
+//
+// 因此普通的键读写只需一条GET或SET，任意内容的值都不会被误认为滑动过期的值
+const (
+	redisFlag        = "\xff"
+	redisSlidingMark = redisFlag + "S"
+	redisEscapeMark  = redisFlag + "E"
+)
+
+// escapeRedisValue 转义以redisFlag开头的普通值
+func escapeRedisValue(str string) string {
+	if strings.HasPrefix(str, redisFlag) {
+		return redisEscapeMark + str
+	}
+	return str
+}
+
+// slidingHeader 返回滑动时长为ttl的值的头部
+func slidingHeader(ttl time.Duration) string {
+	return redisSlidingMark + strconv.FormatInt(redisMillis(ttl), 10) + ":"
+}
+
+// redisMillis 把过期时长转换为传给脚本的毫秒数，正的时长向上取整，
+// 避免不足1毫秒的时长变成0而被当作永不过期
+func redisMillis(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64((d + time.Millisecond - 1) / time.Millisecond)
+}
+
+// decodeRedisValue 去掉值的标记，返回原始值和滑动时长，不是滑动过期的值时滑动时长为0
+func decodeRedisValue(raw string) (string, time.Duration) {
+	if !strings.HasPrefix(raw, redisFlag) {
+		return raw, 0
+	}
+	if value, ok := strings.CutPrefix(raw, redisEscapeMark); ok {
+		return value, 0
+	}
+	rest, ok := strings.CutPrefix(raw, redisSlidingMark)
+	if !ok {
+		return raw, 0
+	}
+	ms, value, ok := strings.Cut(rest, ":")
+	if !ok {
+		return raw, 0
+	}
+	n, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return raw, 0
+	}
+	return value, time.Duration(n) * time.Millisecond
+}
+
+// slidingTouchScript 对KEYS中的每个键，值仍以头部ARGV[2i-1]开头时把过期时间重置为ARGV[2i]毫秒，
+// 读取之后被Set覆盖的键不受影响。GETRANGE只取头部，不读出整个值
+var slidingTouchScript = redis.NewScript(`
+for i, key in ipairs(KEYS) do
+	local header = ARGV[2 * i - 1]
+	if redis.call('GETRANGE', key, 0, #header - 1) == header then
+		redis.call('PEXPIRE', key, ARGV[2 * i])
+	end
+end
+return 1
+`)
+
+// touchSliding 在一次往返中延长命中的滑动过期的键的过期时间
+func (r *RedisCache) touchSliding(ctx context.Context, keys []string, ttls []time.Duration) error {
+	prefixed := make([]string, len(keys))
+	args := make([]interface{}, 0, len(keys)*2)
+	for i, key := range keys {
+		prefixed[i] = r.prefixKey + key
+		args = append(args, slidingHeader(ttls[i]), redisMillis(ttls[i]))
+	}
+	return slidingTouchScript.Run(ctx, r.client, prefixed, args...).Err()
+}
+
+// GetAndTouch 使用GETEX获取指定键的值，并将过期时间重置为ttl，ttl<=0表示永不过期
+func (r *RedisCache) GetAndTouch(key string, ttl time.Duration) (string, error) {
+	raw, err := r.client.GetEx(r.ctx, r.prefixKey+key, max(ttl, 0)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrKeyNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	value, _ := decodeRedisValue(raw)
+	return value, nil
+}
+
+// SetSliding 写入滑动过期的键值对，之后每次Get或GetMulti命中都将过期时间重置为ttl。
+// 滑动时长以头部的形式保存在值中，直接读取Redis的其他客户端会看到该头部
+func (r *RedisCache) SetSliding(key string, value interface{}, ttl time.Duration) error {
+	if ttl <= 0 {
+		return r.Set(key, value, 0)
+	}
+	str, err := encodeValue(r.codec, value)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(r.ctx, r.prefixKey+key, slidingHeader(ttl)+str, ttl).Err()
 }
 
 // Delete 从缓存中删除指定键
 func (r *RedisCache) Delete(key string) error {
-	return r.client.Del(r.ctx, r.prefixKey+key).Err()
+	return r.DeleteCtx(r.ctx, key)
+}
+
+// DeleteCtx 从缓存中删除指定键
+func (r *RedisCache) DeleteCtx(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.prefixKey+key).Err()
 }
 
 // Exists 检查指定键是否存在于缓存中
 func (r *RedisCache) Exists(key string) (bool, error) {
-	result, err := r.client.Exists(r.ctx, r.prefixKey+key).Result()
+	return r.ExistsCtx(r.ctx, key)
+}
+
+// ExistsCtx 检查指定键是否存在于缓存中
+func (r *RedisCache) ExistsCtx(ctx context.Context, key string) (bool, error) {
+	result, err := r.client.Exists(ctx, r.prefixKey+key).Result()
 	if err != nil {
 		return false, err
 	}
@@ -60,7 +227,12 @@ func (r *RedisCache) Exists(key string) (bool, error) {
 
 // Expire 设置键的过期时间
 func (r *RedisCache) Expire(key string, expiration time.Duration) error {
-	result, err := r.client.Expire(r.ctx, r.prefixKey+key, expiration).Result()
+	return r.ExpireCtx(r.ctx, key, expiration)
+}
+
+// ExpireCtx 设置键的过期时间
+func (r *RedisCache) ExpireCtx(ctx context.Context, key string, expiration time.Duration) error {
+	result, err := r.client.Expire(ctx, r.prefixKey+key, expiration).Result()
 	if err != nil {
 		return err
 	}
@@ -72,7 +244,12 @@ func (r *RedisCache) Expire(key string, expiration time.Duration) error {
 
 // TTL 获取键的剩余生存时间
 func (r *RedisCache) TTL(key string) (time.Duration, error) {
-	ttl, err := r.client.TTL(r.ctx, r.prefixKey+key).Result()
+	return r.TTLCtx(r.ctx, key)
+}
+
+// TTLCtx 获取键的剩余生存时间
+func (r *RedisCache) TTLCtx(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.TTL(ctx, r.prefixKey+key).Result()
 	if err != nil {
 		return 0, err
 	}
@@ -90,7 +267,379 @@ func (r *RedisCache) TTL(key string) (time.Duration, error) {
 	return ttl, nil
 }
 
+// Codec 返回缓存使用的序列化方式
+func (r *RedisCache) Codec() Codec {
+	return r.codec
+}
+
 // Close 关闭Redis连接
 func (r *RedisCache) Close() error {
 	return r.client.Close()
 }
+
+// GetMulti 使用一条MGET批量获取指定键的值，命中滑动过期的键时再用一次脚本延长它们的过期时间
+func (r *RedisCache) GetMulti(keys []string) (map[string]string, error) {
+	values := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.prefixKey + key
+	}
+	result, err := r.client.MGet(r.ctx, prefixed...).Result()
+	if err != nil {
+		return nil, err
+	}
+	var slidingKeys []string
+	var ttls []time.Duration
+	for i, val := range result {
+		str, ok := val.(string)
+		if !ok {
+			continue
+		}
+		value, sliding := decodeRedisValue(str)
+		values[keys[i]] = value
+		if sliding > 0 {
+			slidingKeys = append(slidingKeys, keys[i])
+			ttls = append(ttls, sliding)
+		}
+	}
+	if len(slidingKeys) > 0 {
+		if err := r.touchSliding(r.ctx, slidingKeys, ttls); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// SetMulti 使用pipeline在一次往返中批量写入键值对
+func (r *RedisCache) SetMulti(items map[string]interface{}, expiration time.Duration) error {
+	errs := make(map[string]error)
+	encoded := encodeItems(r.codec, items, errs)
+	if len(encoded) == 0 {
+		return newBatchError(errs)
+	}
+	for key, value := range encoded {
+		encoded[key] = escapeRedisValue(value)
+	}
+
+	cmds := make(map[string]*redis.StatusCmd, len(encoded))
+	_, err := r.client.Pipelined(r.ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range encoded {
+			cmds[key] = pipe.Set(r.ctx, r.prefixKey+key, value, expiration)
+		}
+		return nil
+	})
+	if err != nil {
+		for key, cmd := range cmds {
+			if cmdErr := cmd.Err(); cmdErr != nil {
+				errs[key] = cmdErr
+			}
+		}
+	}
+	return newBatchError(errs)
+}
+
+// DeleteMulti 使用一条DEL命令批量删除指定键
+func (r *RedisCache) DeleteMulti(keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.prefixKey + key
+	}
+	return r.client.Del(r.ctx, prefixed...).Err()
+}
+
+// incrByScript 原子地执行INCRBY，只有键由本次调用创建时才设置过期时间。
+// 滑动过期的计数器以头部ARGV[3]开头，INCRBY无法处理，返回SLIDING错误；
+// 值不是整数或结果溢出时INCRBY返回以ERR开头的错误，统一返回NOTINT错误，其他错误（如WRONGTYPE）原样返回
+var incrByScript = redis.NewScript(`
+if redis.call('GETRANGE', KEYS[1], 0, #ARGV[3] - 1) == ARGV[3] then
+	return redis.error_reply('SLIDING')
+end
+local created = redis.call('EXISTS', KEYS[1]) == 0
+local value = redis.pcall('INCRBY', KEYS[1], ARGV[1])
+if type(value) == 'table' and value.err then
+	if string.sub(value.err, 1, 4) == 'ERR ' then
+		return redis.error_reply('NOTINT')
+	end
+	return value
+end
+if created and tonumber(ARGV[2]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return value
+`)
+
+// Incr 将计数器加1
+func (r *RedisCache) Incr(key string, expiration time.Duration) (int64, error) {
+	return r.IncrBy(key, 1, expiration)
+}
+
+// Decr 将计数器减1
+func (r *RedisCache) Decr(key string, expiration time.Duration) (int64, error) {
+	return r.IncrBy(key, -1, expiration)
+}
+
+// DecrBy 将计数器减delta
+func (r *RedisCache) DecrBy(key string, delta int64, expiration time.Duration) (int64, error) {
+	if delta == math.MinInt64 {
+		return 0, ErrNotInteger
+	}
+	return r.IncrBy(key, -delta, expiration)
+}
+
+// IncrBy 使用INCRBY将计数器加delta，计数器不存在时以expiration创建；
+// 滑动过期的计数器用WATCH事务读取、修改并写回，保留头部和过期时间
+func (r *RedisCache) IncrBy(key string, delta int64, expiration time.Duration) (int64, error) {
+	for {
+		n, err := incrByScript.Run(r.ctx, r.client, []string{r.prefixKey + key}, delta, redisMillis(expiration), redisSlidingMark).Int64()
+		if isRedisError(err, "SLIDING") {
+			n, err = r.incrSliding(key, delta)
+			if errors.Is(err, redis.TxFailedErr) || errors.Is(err, errNotSliding) {
+				// 键在读取和写回之间被修改，重试
+				continue
+			}
+		}
+		if isRedisError(err, "NOTINT") {
+			return 0, ErrNotInteger
+		}
+		return n, err
+	}
+}
+
+// isRedisError 判断err是否为脚本用redis.error_reply返回的reply错误
+func isRedisError(err error, reply string) bool {
+	var redisErr redis.Error
+	return errors.As(err, &redisErr) && redisErr.Error() == reply
+}
+
+// errNotSliding 键在incrSliding读取时已不是滑动过期的值
+var errNotSliding = errors.New("go_cache: not a sliding value")
+
+// incrSliding 在WATCH事务中把滑动过期的计数器加delta，键被并发修改时返回redis.TxFailedErr
+func (r *RedisCache) incrSliding(key string, delta int64) (int64, error) {
+	var n int64
+	err := r.client.Watch(r.ctx, func(tx *redis.Tx) error {
+		raw, err := tx.Get(r.ctx, r.prefixKey+key).Result()
+		if errors.Is(err, redis.Nil) {
+			return errNotSliding
+		}
+		if err != nil {
+			return err
+		}
+		value, sliding := decodeRedisValue(raw)
+		if sliding <= 0 {
+			return errNotSliding
+		}
+		if n, err = incrValue(value, delta); err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(r.ctx, func(pipe redis.Pipeliner) error {
+			pipe.SetArgs(r.ctx, r.prefixKey+key, slidingHeader(sliding)+strconv.FormatInt(n, 10), redis.SetArgs{KeepTTL: true})
+			return nil
+		})
+		return err
+	}, r.prefixKey+key)
+	return n, err
+}
+
+// compareAndSwapScript 当前值等于转义后的ARGV[1]，或者是以头部ARGV[4]开头、去掉头部后等于ARGV[5]的滑动过期的值时，
+// 写入ARGV[2]，ARGV[3]为过期毫秒数
+var compareAndSwapScript = redis.NewScript(`
+local cur = redis.call('GET', KEYS[1])
+if cur ~= ARGV[1] then
+	local sep = cur and string.sub(cur, 1, #ARGV[4]) == ARGV[4] and string.find(cur, ':', #ARGV[4] + 1, true)
+	if not sep or string.sub(cur, sep + 1) ~= ARGV[5] then
+		return 0
+	end
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+else
+	redis.call('SET', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// SetNX 使用SET NX仅当键不存在时写入
+func (r *RedisCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	str, err := r.encode(value)
+	if err != nil {
+		return false, err
+	}
+	return r.client.SetNX(r.ctx, r.prefixKey+key, str, expiration).Result()
+}
+
+// SetXX 使用SET XX仅当键已存在时写入
+func (r *RedisCache) SetXX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	str, err := r.encode(value)
+	if err != nil {
+		return false, err
+	}
+	return r.client.SetXX(r.ctx, r.prefixKey+key, str, expiration).Result()
+}
+
+// CompareAndSwap 使用Lua脚本原子地比较并替换，当前值为滑动过期的值时比较去掉头部后的值，写入后不再滑动
+func (r *RedisCache) CompareAndSwap(key string, old, new interface{}, expiration time.Duration) (bool, error) {
+	oldStr, err := encodeValue(r.codec, old)
+	if err != nil {
+		return false, err
+	}
+	newStr, err := r.encode(new)
+	if err != nil {
+		return false, err
+	}
+	n, err := compareAndSwapScript.Run(r.ctx, r.client, []string{r.prefixKey + key},
+		escapeRedisValue(oldStr), newStr, redisMillis(expiration), redisSlidingMark, oldStr).Int64()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// redisScanCount 每次SCAN建议返回的键数量
+const redisScanCount = 100
+
+// Keys 使用SCAN MATCH返回匹配pattern的所有键，不会像KEYS命令一样阻塞Redis
+func (r *RedisCache) Keys(pattern string) ([]string, error) {
+	keys, err := collectKeys(r.scan(escapePattern(r.prefixKey) + pattern))
+	if err != nil {
+		return nil, err
+	}
+	// SCAN可能返回重复的键
+	return uniqueKeys(keys), nil
+}
+
+// Scan 返回以prefix开头的键的迭代器，按需分批执行SCAN，同一个键可能被返回多次
+func (r *RedisCache) Scan(prefix string) KeyIterator {
+	return r.scan(escapePattern(r.prefixKey+prefix) + "*")
+}
+
+func (r *RedisCache) scan(match string) *redisKeyIterator {
+	return &redisKeyIterator{
+		ctx:       r.ctx,
+		iter:      r.client.Scan(r.ctx, 0, match, redisScanCount).Iterator(),
+		prefixKey: r.prefixKey,
+	}
+}
+
+// redisKeyIterator 包装redis.ScanIterator并去掉键的前缀
+type redisKeyIterator struct {
+	ctx       context.Context
+	iter      *redis.ScanIterator
+	prefixKey string
+}
+
+func (it *redisKeyIterator) Next() bool {
+	for it.iter.Next(it.ctx) {
+		// 跳过内部使用的标签集合
+		if !strings.HasPrefix(it.Key(), redisTagPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *redisKeyIterator) Key() string {
+	return strings.TrimPrefix(it.iter.Val(), it.prefixKey)
+}
+
+func (it *redisKeyIterator) Err() error {
+	return it.iter.Err()
+}
+
+// Clear 使用SCAN+UNLINK分批删除prefixKey下的所有键，不会执行FLUSHDB。
+// prefixKey为空时会删除整个数据库的键，为避免误删直接返回ErrInvalidParameter
+func (r *RedisCache) Clear() error {
+	if r.prefixKey == "" {
+		return ErrInvalidParameter
+	}
+
+	iter := r.client.Scan(r.ctx, 0, escapePattern(r.prefixKey)+"*", redisScanCount).Iterator()
+	batch := make([]string, 0, redisScanCount)
+	for iter.Next(r.ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) >= redisScanCount {
+			if err := r.client.Unlink(r.ctx, batch...).Err(); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return r.client.Unlink(r.ctx, batch...).Err()
+	}
+	return nil
+}
+
+// redisTagPrefix 标签集合的键前缀（位于prefixKey之后），键枚举时会被过滤
+const redisTagPrefix = "__tag__:"
+
+// setWithTagsScript 写入键并加入每个标签集合（KEYS[2:]），标签集合的过期时间不短于其中的键
+var setWithTagsScript = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ttl)
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+	local existed = redis.call('EXISTS', KEYS[i])
+	local current = redis.call('PTTL', KEYS[i])
+	redis.call('SADD', KEYS[i], ARGV[3])
+	if ttl <= 0 then
+		if existed == 1 and current >= 0 then
+			redis.call('PERSIST', KEYS[i])
+		end
+	elseif existed == 0 or (current >= 0 and current < ttl) then
+		redis.call('PEXPIRE', KEYS[i], ttl)
+	end
+end
+return 1
+`)
+
+// invalidateTagScript 删除标签集合KEYS[1]中的所有键以及集合本身，ARGV[1]为键前缀
+var invalidateTagScript = redis.NewScript(`
+local members = redis.call('SMEMBERS', KEYS[1])
+for _, member in ipairs(members) do
+	redis.call('UNLINK', ARGV[1] .. member)
+end
+redis.call('DEL', KEYS[1])
+return #members
+`)
+
+// SetWithTags 将键值对存储到缓存中，并把键加入每个标签对应的Redis集合。
+// 之后用Set覆盖该键不会解除标签关联，InvalidateTag仍会删除它
+func (r *RedisCache) SetWithTags(key string, value interface{}, expiration time.Duration, tags ...string) error {
+	str, err := r.encode(value)
+	if err != nil {
+		return err
+	}
+	keys := []string{r.prefixKey + key}
+	for _, tag := range uniqueKeys(append([]string(nil), tags...)) {
+		keys = append(keys, r.tagKey(tag))
+	}
+	return setWithTagsScript.Run(r.ctx, r.client, keys, str, redisMillis(expiration), key).Err()
+}
+
+// InvalidateTag 原子地删除标签集合中的所有键
+func (r *RedisCache) InvalidateTag(tag string) error {
+	return invalidateTagScript.Run(r.ctx, r.client, []string{r.tagKey(tag)}, r.prefixKey).Err()
+}
+
+// TagKeys 返回标签集合中记录的所有键
+func (r *RedisCache) TagKeys(tag string) ([]string, error) {
+	return r.client.SMembers(r.ctx, r.tagKey(tag)).Result()
+}
+
+func (r *RedisCache) tagKey(tag string) string {
+	return r.prefixKey + redisTagPrefix + tag
+}
@@ -1,6 +1,7 @@
 package go_cache
 
 import (
+	"context"
 	"time"
 )
 
@@ -27,3 +28,133 @@ type Cache interface {
 	// Close 关闭缓存连接
 	Close() error
 }
+
+// ContextCache 定义了支持context的缓存接口，调用方可以通过ctx取消请求或传递超时
+type ContextCache interface {
+	// SetCtx 将键值对存储到缓存中，并设置过期时间
+	SetCtx(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+
+	// GetCtx 从缓存中获取指定键的值
+	GetCtx(ctx context.Context, key string) (string, error)
+
+	// DeleteCtx 从缓存中删除指定键
+	DeleteCtx(ctx context.Context, key string) error
+
+	// ExistsCtx 检查指定键是否存在于缓存中
+	ExistsCtx(ctx context.Context, key string) (bool, error)
+
+	// ExpireCtx 设置键的过期时间
+	ExpireCtx(ctx context.Context, key string, expiration time.Duration) error
+
+	// TTLCtx 获取键的剩余生存时间
+	TTLCtx(ctx context.Context, key string) (time.Duration, error)
+
+	// Close 关闭缓存连接
+	Close() error
+}
+
+// BatchCache 定义了批量操作接口，用于减少逐个键访问的往返开销
+type BatchCache interface {
+	// GetMulti 批量获取指定键的值，结果中只包含找到的键；
+	// 个别键读取失败时返回*BatchError，其余键的结果仍然有效
+	GetMulti(keys []string) (map[string]string, error)
+
+	// SetMulti 批量将键值对存储到缓存中，所有键使用相同的过期时间；
+	// 个别键写入失败时返回*BatchError
+	SetMulti(items map[string]interface{}, expiration time.Duration) error
+
+	// DeleteMulti 批量删除指定键；个别键删除失败时返回*BatchError
+	DeleteMulti(keys []string) error
+}
+
+// CounterCache 定义了原子计数器接口。
+// expiration只在计数器被创建时（键不存在或已过期）生效，已存在的计数器保持原有的过期时间，0表示永不过期
+type CounterCache interface {
+	// Incr 将计数器加1，返回加之后的值
+	Incr(key string, expiration time.Duration) (int64, error)
+
+	// IncrBy 将计数器加delta，返回加之后的值
+	IncrBy(key string, delta int64, expiration time.Duration) (int64, error)
+
+	// Decr 将计数器减1，返回减之后的值
+	Decr(key string, expiration time.Duration) (int64, error)
+
+	// DecrBy 将计数器减delta，返回减之后的值
+	DecrBy(key string, delta int64, expiration time.Duration) (int64, error)
+}
+
+// ConditionalCache 定义了条件写入接口，返回值表示是否实际写入
+type ConditionalCache interface {
+	// SetNX 仅当键不存在时写入
+	SetNX(key string, value interface{}, expiration time.Duration) (bool, error)
+
+	// SetXX 仅当键已存在时写入
+	SetXX(key string, value interface{}, expiration time.Duration) (bool, error)
+
+	// CompareAndSwap 仅当键的当前值等于old（按缓存的Codec序列化后比较）时写入new
+	CompareAndSwap(key string, old, new interface{}, expiration time.Duration) (bool, error)
+}
+
+// ScanCache 定义了键枚举接口，只返回未过期的键
+type ScanCache interface {
+	// Keys 返回匹配pattern的所有键，pattern使用与Redis相同的glob语法（*、?、[abc]、[^a]、[a-z]、\转义）
+	Keys(pattern string) ([]string, error)
+
+	// Scan 返回以prefix开头的键的迭代器，适合键数量很多的场景
+	Scan(prefix string) KeyIterator
+}
+
+// KeyIterator 键迭代器
+//
+//	iter := cache.Scan("user:")
+//	for iter.Next() {
+//		fmt.Println(iter.Key())
+//	}
+//	if err := iter.Err(); err != nil {
+//		...
+//	}
+type KeyIterator interface {
+	// Next 移动到下一个键，没有更多键或出错时返回false
+	Next() bool
+
+	// Key 返回当前键
+	Key() string
+
+	// Err 返回迭代过程中遇到的错误
+	Err() error
+}
+
+// ClearableCache 定义了清空缓存的接口
+type ClearableCache interface {
+	// Clear 删除缓存中的所有键
+	Clear() error
+}
+
+// TagCache 定义了基于标签的批量失效接口
+type TagCache interface {
+	// SetWithTags 将键值对存储到缓存中并关联标签
+	SetWithTags(key string, value interface{}, expiration time.Duration, tags ...string) error
+
+	// InvalidateTag 删除关联了tag的所有键
+	InvalidateTag(tag string) error
+
+	// TagKeys 返回关联了tag的所有键
+	TagKeys(tag string) ([]string, error)
+}
+
+// EvictObserver 定义了监听条目离开缓存的接口
+type EvictObserver interface {
+	// OnEvict 注册监听函数，条目因过期、淘汰、删除或覆盖离开缓存时调用。
+	// 监听函数在释放缓存锁之后调用，可以再次访问缓存；可以注册多个，按注册顺序调用
+	OnEvict(fn func(key, value string, reason EvictReason))
+}
+
+// SlidingCache 定义了滑动过期（读取时续期）的接口
+type SlidingCache interface {
+	// GetAndTouch 获取指定键的值，并将过期时间重置为ttl，ttl<=0表示永不过期
+	GetAndTouch(key string, ttl time.Duration) (string, error)
+
+	// SetSliding 写入滑动过期的键值对，之后每次Get或GetMulti命中都将过期时间重置为读取时刻加ttl；
+	// Exists和TTL不续期，再次用Set等方法写入后不再滑动
+	SetSliding(key string, value interface{}, ttl time.Duration) error
+}
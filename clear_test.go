@@ -0,0 +1,60 @@
+package go_cache
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestClearableCache(t *testing.T) {
+	defer Init()()
+	fileCache, err := NewFileCache(testFilePath)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	caches := map[string]ClearableCache{
+		"redis":   redisServer,
+		"memory":  NewMemoryCache(),
+		"arena":   newArenaTestCache(),
+		"file":    fileCache,
+		"disklog": newDiskLogTestCache(t, nil),
+		"multi":   NewMultiCache(NewMemoryCache(), fileCache),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			c := cache.(Cache)
+			for _, key := range []string{"clear_1", "clear_2", "clear_3"} {
+				if err := c.Set(key, "v", time.Minute); err != nil {
+					t.Fatalf("设置键值对失败: %v", err)
+				}
+			}
+			if err := cache.Clear(); err != nil {
+				t.Fatalf("清空缓存失败: %v", err)
+			}
+			for _, key := range []string{"clear_1", "clear_2", "clear_3"} {
+				if exists, _ := c.Exists(key); exists {
+					t.Errorf("期望键 %s 已被清空", key)
+				}
+			}
+			// 清空后仍可正常使用
+			if err := c.Set("clear_1", "v", time.Minute); err != nil {
+				t.Fatalf("清空后设置键值对失败: %v", err)
+			}
+			_ = c.Delete("clear_1")
+		})
+	}
+
+	if _, err := os.Stat(testFilePath); err != nil {
+		t.Errorf("期望保留缓存目录, 实际: %v", err)
+	}
+}
+
+func TestRedisCache_ClearWithoutPrefix(t *testing.T) {
+	cache := NewRedisCache(redisUrl, redisPassword, redisDb, "")
+	defer cache.Close()
+	if err := cache.Clear(); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("期望没有前缀时拒绝清空, 实际: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package go_cache
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock 提供当前时间和定时任务，用于在测试中控制过期时间
+type Clock interface {
+	// Now 返回当前时间
+	Now() time.Time
+
+	// Every 每隔d调用一次fn，返回停止函数
+	Every(d time.Duration, fn func()) (stop func())
+}
+
+// realClock 使用系统时间
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Every(d time.Duration, fn func()) func() {
+	ticker := time.NewTicker(d)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fn()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// clockOrSystem 在clock为nil时返回系统时钟
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return realClock{}
+	}
+	return clock
+}
+
+// FakeClock 是只在调用Advance时前进的时钟，用于测试过期逻辑而不需要等待
+type FakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	tasks []*fakeTask
+}
+
+// fakeTask 是FakeClock上的一个定时任务
+type fakeTask struct {
+	interval time.Duration
+	next     time.Time
+	fn       func()
+	stopped  bool
+}
+
+// NewFakeClock 创建一个从now开始的FakeClock
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now 返回当前时间
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Every 注册定时任务，任务只在Advance经过其触发时间时同步调用
+func (c *FakeClock) Every(d time.Duration, fn func()) func() {
+	if d <= 0 {
+		panic("go_cache: non-positive interval for FakeClock.Every")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	task := &fakeTask{interval: d, next: c.now.Add(d), fn: fn}
+	c.tasks = append(c.tasks, task)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		task.stopped = true
+	}
+}
+
+// Advance 将时间前进d，并按时间顺序同步调用期间到期的定时任务，
+// 每次调用任务时Now返回该次触发的时间；返回时所有到期的任务都已执行完毕
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	for {
+		task := c.nextTaskLocked(target)
+		if task == nil {
+			break
+		}
+		c.now = task.next
+		task.next = task.next.Add(task.interval)
+		c.mu.Unlock()
+		task.fn()
+		c.mu.Lock()
+	}
+	c.now = target
+	c.mu.Unlock()
+}
+
+// nextTaskLocked 返回在target之前最早到期的任务，并移除已停止的任务
+func (c *FakeClock) nextTaskLocked(target time.Time) *fakeTask {
+	tasks := c.tasks[:0]
+	for _, task := range c.tasks {
+		if !task.stopped {
+			tasks = append(tasks, task)
+		}
+	}
+	c.tasks = tasks
+	sort.SliceStable(c.tasks, func(i, j int) bool {
+		return c.tasks[i].next.Before(c.tasks[j].next)
+	})
+	if len(c.tasks) == 0 || c.tasks[0].next.After(target) {
+		return nil
+	}
+	return c.tasks[0]
+}
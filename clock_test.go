@@ -0,0 +1,72 @@
+package go_cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFakeClock_Every(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	var fired []string
+	stopA := clock.Every(2*time.Second, func() {
+		fired = append(fired, fmt.Sprintf("a@%v", clock.Now().Sub(start)))
+	})
+	clock.Every(3*time.Second, func() {
+		fired = append(fired, fmt.Sprintf("b@%v", clock.Now().Sub(start)))
+	})
+
+	clock.Advance(6 * time.Second)
+	want := "[a@2s b@3s a@4s a@6s b@6s]"
+	if fmt.Sprint(fired) != want {
+		t.Errorf("期望任务按时间顺序触发 %s, 实际 %v", want, fired)
+	}
+	if now := clock.Now(); !now.Equal(start.Add(6 * time.Second)) {
+		t.Errorf("期望时间前进6秒, 实际 %v", now.Sub(start))
+	}
+
+	fired = nil
+	stopA()
+	clock.Advance(3 * time.Second)
+	if fmt.Sprint(fired) != "[b@9s]" {
+		t.Errorf("期望停止的任务不再触发, 实际 %v", fired)
+	}
+}
+
+func TestFakeClock_TTL(t *testing.T) {
+	defer Init()()
+	clock := NewFakeClock(time.Now())
+	fileCache, err := NewFileCacheWithOptions(testFilePath, FileCacheOptions{Clock: clock})
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	caches := map[string]Cache{
+		"memory":  NewMemoryCacheWithOptions(MemoryCacheOptions{Clock: clock}),
+		"arena":   NewMemoryCacheWithOptions(MemoryCacheOptions{Clock: clock, Storage: ArenaStorage}),
+		"file":    fileCache,
+		"disklog": newDiskLogTestCache(t, clock),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			defer cache.Close()
+			_ = cache.Set("key", "value", time.Minute)
+
+			clock.Advance(20 * time.Second)
+			if ttl, err := cache.TTL("key"); err != nil || ttl != 40*time.Second {
+				t.Errorf("期望剩余40秒, 实际 %v, %v", ttl, err)
+			}
+
+			clock.Advance(40 * time.Second)
+			if exists, _ := cache.Exists("key"); !exists {
+				t.Error("期望到达过期时间的瞬间键仍然存在")
+			}
+			clock.Advance(time.Nanosecond)
+			if _, err := cache.Get("key"); err != ErrKeyNotFound {
+				t.Errorf("期望过期后返回ErrKeyNotFound, 实际 %v", err)
+			}
+		})
+	}
+}
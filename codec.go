@@ -0,0 +1,115 @@
+package go_cache
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+// Codec 定义了缓存值的序列化方式
+type Codec interface {
+	// Marshal 将值序列化为字节
+	Marshal(v any) ([]byte, error)
+
+	// Unmarshal 将字节反序列化到v中，v必须是指针
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec 使用encoding/json进行序列化，是所有缓存的默认Codec
+type JSONCodec struct{}
+
+// Marshal 将值序列化为JSON
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal 将JSON反序列化到v中
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// GobCodec 使用encoding/gob进行序列化，适合Go进程之间共享的缓存
+type GobCodec struct{}
+
+// Marshal 将值序列化为gob
+func (GobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal 将gob反序列化到v中
+func (GobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// protoMarshaler 由gogo/protobuf、vtprotobuf等生成的消息类型实现
+type protoMarshaler interface {
+	Marshal() ([]byte, error)
+}
+
+// protoUnmarshaler 由gogo/protobuf、vtprotobuf等生成的消息类型实现
+type protoUnmarshaler interface {
+	Unmarshal(data []byte) error
+}
+
+// ProtoCodec 调用值自身的Marshal/Unmarshal方法进行序列化，
+// 兼容gogo/protobuf、vtprotobuf生成的消息以及实现了encoding.BinaryMarshaler的类型
+type ProtoCodec struct{}
+
+// Marshal 将值序列化为protobuf二进制
+func (ProtoCodec) Marshal(v any) ([]byte, error) {
+	switch m := v.(type) {
+	case protoMarshaler:
+		return m.Marshal()
+	case encoding.BinaryMarshaler:
+		return m.MarshalBinary()
+	default:
+		return nil, fmt.Errorf("%w: %T does not implement Marshal() ([]byte, error)", ErrInvalidParameter, v)
+	}
+}
+
+// Unmarshal 将protobuf二进制反序列化到v中
+func (ProtoCodec) Unmarshal(data []byte, v any) error {
+	switch m := v.(type) {
+	case protoUnmarshaler:
+		return m.Unmarshal(data)
+	case encoding.BinaryUnmarshaler:
+		return m.UnmarshalBinary(data)
+	default:
+		return fmt.Errorf("%w: %T does not implement Unmarshal([]byte) error", ErrInvalidParameter, v)
+	}
+}
+
+// encodeValue 将写入缓存的值转换为字符串，string和[]byte原样保存，其余类型使用codec序列化
+func encodeValue(codec Codec, value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case nil:
+		return "", nil
+	}
+
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	data, err := codec.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSerialization, err)
+	}
+	return string(data), nil
+}
+
+// codecOf 返回缓存使用的Codec，未知实现返回JSONCodec
+func codecOf(cache any) Codec {
+	if c, ok := cache.(interface{ Codec() Codec }); ok && c.Codec() != nil {
+		return c.Codec()
+	}
+	return JSONCodec{}
+}
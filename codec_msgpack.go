@@ -0,0 +1,716 @@
+package go_cache
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MsgpackCodec 使用MessagePack格式进行紧凑的二进制序列化。
+// 结构体按字段名编码为map，支持`msgpack:"name"`标签，"-"表示忽略该字段；
+// 实现了encoding.BinaryMarshaler的类型（如time.Time）编码为bin。
+type MsgpackCodec struct{}
+
+// Marshal 将值序列化为MessagePack
+func (MsgpackCodec) Marshal(v any) ([]byte, error) {
+	e := &msgpackEncoder{}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// Unmarshal 将MessagePack反序列化到v中
+func (MsgpackCodec) Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w: msgpack: Unmarshal(non-pointer %T)", ErrInvalidParameter, v)
+	}
+	d := &msgpackDecoder{data: data}
+	if err := d.decode(rv.Elem()); err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return fmt.Errorf("msgpack: %d trailing bytes", len(d.data)-d.pos)
+	}
+	return nil
+}
+
+var (
+	binaryMarshalerType   = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+)
+
+// msgpackMaxDepth 编码和解码允许的最大嵌套层数，超过时返回错误而不是耗尽栈
+const msgpackMaxDepth = 1000
+
+type msgpackEncoder struct {
+	buf   bytes.Buffer
+	depth int
+	seen  map[msgpackRef]struct{} // 正在编码的指针、map和切片，用于发现循环引用
+}
+
+// msgpackRef 标识一个引用类型的值，切片还需要长度才能区分共享底层数组的不同切片
+type msgpackRef struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+func (e *msgpackEncoder) writeUint(prefix byte, n uint64, size int) {
+	e.buf.WriteByte(prefix)
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], n)
+	e.buf.Write(tmp[8-size:])
+}
+
+func (e *msgpackEncoder) encodeInt(n int64) {
+	switch {
+	case n >= 0:
+		e.encodeUint(uint64(n))
+	case n >= -32:
+		e.buf.WriteByte(byte(n))
+	case n >= math.MinInt8:
+		e.writeUint(0xd0, uint64(uint8(n)), 1)
+	case n >= math.MinInt16:
+		e.writeUint(0xd1, uint64(uint16(n)), 2)
+	case n >= math.MinInt32:
+		e.writeUint(0xd2, uint64(uint32(n)), 4)
+	default:
+		e.writeUint(0xd3, uint64(n), 8)
+	}
+}
+
+func (e *msgpackEncoder) encodeUint(n uint64) {
+	switch {
+	case n <= 0x7f:
+		e.buf.WriteByte(byte(n))
+	case n <= math.MaxUint8:
+		e.writeUint(0xcc, n, 1)
+	case n <= math.MaxUint16:
+		e.writeUint(0xcd, n, 2)
+	case n <= math.MaxUint32:
+		e.writeUint(0xce, n, 4)
+	default:
+		e.writeUint(0xcf, n, 8)
+	}
+}
+
+func (e *msgpackEncoder) encodeString(s string) {
+	n := uint64(len(s))
+	switch {
+	case n < 32:
+		e.buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		e.writeUint(0xd9, n, 1)
+	case n <= math.MaxUint16:
+		e.writeUint(0xda, n, 2)
+	default:
+		e.writeUint(0xdb, n, 4)
+	}
+	e.buf.WriteString(s)
+}
+
+func (e *msgpackEncoder) encodeBytes(b []byte) {
+	n := uint64(len(b))
+	switch {
+	case n <= math.MaxUint8:
+		e.writeUint(0xc4, n, 1)
+	case n <= math.MaxUint16:
+		e.writeUint(0xc5, n, 2)
+	default:
+		e.writeUint(0xc6, n, 4)
+	}
+	e.buf.Write(b)
+}
+
+func (e *msgpackEncoder) encodeArrayLen(n int) {
+	switch {
+	case n < 16:
+		e.buf.WriteByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		e.writeUint(0xdc, uint64(n), 2)
+	default:
+		e.writeUint(0xdd, uint64(n), 4)
+	}
+}
+
+func (e *msgpackEncoder) encodeMapLen(n int) {
+	switch {
+	case n < 16:
+		e.buf.WriteByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		e.writeUint(0xde, uint64(n), 2)
+	default:
+		e.writeUint(0xdf, uint64(n), 4)
+	}
+}
+
+func (e *msgpackEncoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf.WriteByte(0xc0)
+		return nil
+	}
+
+	if e.depth++; e.depth > msgpackMaxDepth {
+		return fmt.Errorf("msgpack: exceeded max depth %d", msgpackMaxDepth)
+	}
+	defer func() { e.depth-- }()
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			break
+		}
+		ref := msgpackRef{ptr: v.Pointer(), typ: v.Type()}
+		if v.Kind() == reflect.Slice {
+			ref.len = v.Len()
+		}
+		if _, ok := e.seen[ref]; ok {
+			return fmt.Errorf("msgpack: encountered a cycle via %s", v.Type())
+		}
+		if e.seen == nil {
+			e.seen = make(map[msgpackRef]struct{})
+		}
+		e.seen[ref] = struct{}{}
+		defer delete(e.seen, ref)
+	}
+
+	nilable := v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface
+	if v.Type().Implements(binaryMarshalerType) && !(nilable && v.IsNil()) {
+		data, err := v.Interface().(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		e.encodeBytes(data)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf.WriteByte(0xc3)
+		} else {
+			e.buf.WriteByte(0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.encodeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.encodeUint(v.Uint())
+	case reflect.Float32:
+		e.writeUint(0xca, uint64(math.Float32bits(float32(v.Float()))), 4)
+	case reflect.Float64:
+		e.writeUint(0xcb, math.Float64bits(v.Float()), 8)
+	case reflect.String:
+		e.encodeString(v.String())
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			e.buf.WriteByte(0xc0)
+			return nil
+		}
+		return e.encode(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf.WriteByte(0xc0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.encodeBytes(v.Bytes())
+			return nil
+		}
+		return e.encodeArray(v)
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			e.encodeBytes(b)
+			return nil
+		}
+		return e.encodeArray(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf.WriteByte(0xc0)
+			return nil
+		}
+		return e.encodeMap(v)
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) encodeArray(v reflect.Value) error {
+	e.encodeArrayLen(v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeMap 按编码后的键排序，保证相同的map总是得到相同的字节
+func (e *msgpackEncoder) encodeMap(v reflect.Value) error {
+	type entry struct {
+		key []byte
+		val reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		ke := &msgpackEncoder{depth: e.depth, seen: e.seen}
+		if err := ke.encode(iter.Key()); err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: ke.buf.Bytes(), val: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	e.encodeMapLen(len(entries))
+	for _, en := range entries {
+		e.buf.Write(en.key)
+		if err := e.encode(en.val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) encodeStruct(v reflect.Value) error {
+	fields := msgpackFields(v.Type())
+	e.encodeMapLen(len(fields))
+	for _, f := range fields {
+		e.encodeString(f.name)
+		if err := e.encode(v.Field(f.index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type msgpackField struct {
+	name  string
+	index int
+}
+
+// msgpackFields 返回结构体中需要编码的导出字段
+func msgpackFields(t reflect.Type) []msgpackField {
+	fields := make([]msgpackField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := sf.Name
+		if tag, ok := sf.Tag.Lookup("msgpack"); ok {
+			tag, _, _ = strings.Cut(tag, ",")
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, msgpackField{name: name, index: i})
+	}
+	return fields
+}
+
+type msgpackDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (d *msgpackDecoder) errShort() error {
+	return fmt.Errorf("msgpack: unexpected end of data at offset %d", d.pos)
+}
+
+func (d *msgpackDecoder) readByte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, d.errShort()
+	}
+	b := d.data[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *msgpackDecoder) readN(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, d.errShort()
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *msgpackDecoder) readUint(size int) (uint64, error) {
+	b, err := d.readN(size)
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+// msgpackValue 表示解码得到的一个标量值或容器头部
+type msgpackValue struct {
+	kind   byte // 'n' nil, 'b' bool, 'i' int, 'u' uint, 'f' float, 's' string, 'x' bin, 'a' array, 'm' map
+	b      bool
+	i      int64
+	u      uint64
+	f      float64
+	s      []byte
+	length int
+}
+
+func (d *msgpackDecoder) next() (msgpackValue, error) {
+	c, err := d.readByte()
+	if err != nil {
+		return msgpackValue{}, err
+	}
+
+	var lengthSize int
+	var kind byte
+	switch {
+	case c <= 0x7f:
+		return msgpackValue{kind: 'u', u: uint64(c)}, nil
+	case c >= 0xe0:
+		return msgpackValue{kind: 'i', i: int64(int8(c))}, nil
+	case c&0xf0 == 0x80:
+		return d.container('m', uint64(c&0x0f))
+	case c&0xf0 == 0x90:
+		return d.container('a', uint64(c&0x0f))
+	case c&0xe0 == 0xa0:
+		s, err := d.readN(int(c & 0x1f))
+		return msgpackValue{kind: 's', s: s}, err
+	}
+
+	switch c {
+	case 0xc0:
+		return msgpackValue{kind: 'n'}, nil
+	case 0xc2, 0xc3:
+		return msgpackValue{kind: 'b', b: c == 0xc3}, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.readUint(1 << (c - 0xcc))
+		return msgpackValue{kind: 'u', u: n}, err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		n, err := d.readUint(size)
+		shift := 64 - 8*size
+		return msgpackValue{kind: 'i', i: int64(n<<shift) >> shift}, err
+	case 0xca:
+		n, err := d.readUint(4)
+		return msgpackValue{kind: 'f', f: float64(math.Float32frombits(uint32(n)))}, err
+	case 0xcb:
+		n, err := d.readUint(8)
+		return msgpackValue{kind: 'f', f: math.Float64frombits(n)}, err
+	case 0xd9, 0xda, 0xdb:
+		kind, lengthSize = 's', 1<<(c-0xd9)
+	case 0xc4, 0xc5, 0xc6:
+		kind, lengthSize = 'x', 1<<(c-0xc4)
+	case 0xdc, 0xdd:
+		n, err := d.readUint(2 << (c - 0xdc))
+		if err != nil {
+			return msgpackValue{}, err
+		}
+		return d.container('a', n)
+	case 0xde, 0xdf:
+		n, err := d.readUint(2 << (c - 0xde))
+		if err != nil {
+			return msgpackValue{}, err
+		}
+		return d.container('m', n)
+	default:
+		return msgpackValue{}, fmt.Errorf("msgpack: unsupported format byte 0x%02x", c)
+	}
+
+	n, err := d.readUint(lengthSize)
+	if err != nil {
+		return msgpackValue{}, err
+	}
+	s, err := d.readN(int(n))
+	return msgpackValue{kind: kind, s: s}, err
+}
+
+// container 返回数组或map的头部。每个元素至少占1字节，map的每项至少占2字节，
+// 剩余的数据放不下n个元素时返回错误，避免按损坏的长度分配内存
+func (d *msgpackDecoder) container(kind byte, n uint64) (msgpackValue, error) {
+	minSize := uint64(1)
+	if kind == 'm' {
+		minSize = 2
+	}
+	if remaining := uint64(len(d.data) - d.pos); n > remaining/minSize {
+		return msgpackValue{}, fmt.Errorf("msgpack: length %d exceeds remaining %d bytes at offset %d", n, remaining, d.pos)
+	}
+	return msgpackValue{kind: kind, length: int(n)}, nil
+}
+
+func (d *msgpackDecoder) decode(v reflect.Value) error {
+	if d.depth++; d.depth > msgpackMaxDepth {
+		return fmt.Errorf("msgpack: exceeded max depth %d at offset %d", msgpackMaxDepth, d.pos)
+	}
+	defer func() { d.depth-- }()
+	mv, err := d.next()
+	if err != nil {
+		return err
+	}
+	return d.decodeValue(mv, v)
+}
+
+func (d *msgpackDecoder) decodeValue(mv msgpackValue, v reflect.Value) error {
+	if mv.kind == 'n' {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decodeValue(mv, v.Elem())
+	}
+
+	if mv.kind == 'x' && reflect.PointerTo(v.Type()).Implements(binaryUnmarshalerType) {
+		return v.Addr().Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(mv.s)
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("msgpack: cannot decode into non-empty interface %s", v.Type())
+		}
+		iv, err := d.decodeInterface(mv)
+		if err != nil {
+			return err
+		}
+		if iv == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(reflect.ValueOf(iv))
+		}
+		return nil
+	case reflect.Bool:
+		if mv.kind != 'b' {
+			return d.typeError(mv, v)
+		}
+		v.SetBool(mv.b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch mv.kind {
+		case 'i':
+			n = mv.i
+		case 'u':
+			if mv.u > math.MaxInt64 {
+				return d.typeError(mv, v)
+			}
+			n = int64(mv.u)
+		default:
+			return d.typeError(mv, v)
+		}
+		if v.OverflowInt(n) {
+			return d.typeError(mv, v)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		switch mv.kind {
+		case 'u':
+			n = mv.u
+		case 'i':
+			if mv.i < 0 {
+				return d.typeError(mv, v)
+			}
+			n = uint64(mv.i)
+		default:
+			return d.typeError(mv, v)
+		}
+		if v.OverflowUint(n) {
+			return d.typeError(mv, v)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		switch mv.kind {
+		case 'f':
+			v.SetFloat(mv.f)
+		case 'i':
+			v.SetFloat(float64(mv.i))
+		case 'u':
+			v.SetFloat(float64(mv.u))
+		default:
+			return d.typeError(mv, v)
+		}
+	case reflect.String:
+		if mv.kind != 's' && mv.kind != 'x' {
+			return d.typeError(mv, v)
+		}
+		v.SetString(string(mv.s))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 && (mv.kind == 'x' || mv.kind == 's') {
+			b := make([]byte, len(mv.s))
+			copy(b, mv.s)
+			v.SetBytes(b)
+			return nil
+		}
+		if mv.kind != 'a' {
+			return d.typeError(mv, v)
+		}
+		s := reflect.MakeSlice(v.Type(), mv.length, mv.length)
+		for i := 0; i < mv.length; i++ {
+			if err := d.decode(s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 && (mv.kind == 'x' || mv.kind == 's') {
+			if len(mv.s) != v.Len() {
+				return d.typeError(mv, v)
+			}
+			reflect.Copy(v, reflect.ValueOf(mv.s))
+			return nil
+		}
+		if mv.kind != 'a' || mv.length != v.Len() {
+			return d.typeError(mv, v)
+		}
+		for i := 0; i < mv.length; i++ {
+			if err := d.decode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if mv.kind != 'm' {
+			return d.typeError(mv, v)
+		}
+		m := reflect.MakeMapWithSize(v.Type(), mv.length)
+		for i := 0; i < mv.length; i++ {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := d.decode(key); err != nil {
+				return err
+			}
+			// 键的类型为interface时，解码出的数组或map不能作为键
+			if !key.Comparable() {
+				return fmt.Errorf("msgpack: unhashable map key of type %s at offset %d", key.Elem().Type(), d.pos)
+			}
+			val := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decode(val); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, val)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		if mv.kind != 'm' {
+			return d.typeError(mv, v)
+		}
+		fields := msgpackFields(v.Type())
+		for i := 0; i < mv.length; i++ {
+			var name string
+			if err := d.decode(reflect.ValueOf(&name).Elem()); err != nil {
+				return err
+			}
+			found := false
+			for _, f := range fields {
+				if f.name == name {
+					if err := d.decode(v.Field(f.index)); err != nil {
+						return err
+					}
+					found = true
+					break
+				}
+			}
+			if !found {
+				// 跳过未知字段
+				var discard any
+				if err := d.decode(reflect.ValueOf(&discard).Elem()); err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// decodeInterface 解码到interface{}，整数统一为int64/uint64，map的键为字符串时返回map[string]any
+func (d *msgpackDecoder) decodeInterface(mv msgpackValue) (any, error) {
+	switch mv.kind {
+	case 'n':
+		return nil, nil
+	case 'b':
+		return mv.b, nil
+	case 'i':
+		return mv.i, nil
+	case 'u':
+		if mv.u <= math.MaxInt64 {
+			return int64(mv.u), nil
+		}
+		return mv.u, nil
+	case 'f':
+		return mv.f, nil
+	case 's':
+		return string(mv.s), nil
+	case 'x':
+		b := make([]byte, len(mv.s))
+		copy(b, mv.s)
+		return b, nil
+	case 'a':
+		s := make([]any, mv.length)
+		for i := range s {
+			if err := d.decode(reflect.ValueOf(&s[i]).Elem()); err != nil {
+				return nil, err
+			}
+		}
+		return s, nil
+	case 'm':
+		m := make(map[string]any, mv.length)
+		var generic map[any]any
+		for i := 0; i < mv.length; i++ {
+			var key, val any
+			if err := d.decode(reflect.ValueOf(&key).Elem()); err != nil {
+				return nil, err
+			}
+			if err := d.decode(reflect.ValueOf(&val).Elem()); err != nil {
+				return nil, err
+			}
+			if s, ok := key.(string); ok && generic == nil {
+				m[s] = val
+				continue
+			}
+			if generic == nil {
+				generic = make(map[any]any, mv.length)
+				for k, v := range m {
+					generic[k] = v
+				}
+			}
+			if b, ok := key.([]byte); ok {
+				key = string(b)
+			}
+			if key != nil && !reflect.ValueOf(key).Comparable() {
+				return nil, fmt.Errorf("msgpack: unhashable map key of type %T at offset %d", key, d.pos)
+			}
+			generic[key] = val
+		}
+		if generic != nil {
+			return generic, nil
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("msgpack: unknown value kind %q", mv.kind)
+}
+
+func (d *msgpackDecoder) typeError(mv msgpackValue, v reflect.Value) error {
+	return fmt.Errorf("msgpack: cannot decode %q value into %s at offset %d", mv.kind, v.Type(), d.pos)
+}
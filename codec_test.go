@@ -0,0 +1,241 @@
+package go_cache
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type codecTestItem struct {
+	Name     string
+	Count    int
+	Ratio    float64
+	Enabled  bool
+	Tags     []string
+	Attrs    map[string]int
+	Raw      []byte
+	Created  time.Time
+	Child    *codecTestItem
+	Ignored  string `msgpack:"-"`
+	Renamed  uint16 `msgpack:"r"`
+	internal int
+}
+
+// protoTestMessage 模拟protobuf生成的消息类型
+type protoTestMessage struct {
+	payload string
+}
+
+func (m *protoTestMessage) Marshal() ([]byte, error) {
+	return []byte("pb:" + m.payload), nil
+}
+
+func (m *protoTestMessage) Unmarshal(data []byte) error {
+	m.payload = string(bytes.TrimPrefix(data, []byte("pb:")))
+	return nil
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	value := codecTestItem{
+		Name:    "张三",
+		Count:   -1234567,
+		Ratio:   0.25,
+		Enabled: true,
+		Tags:    []string{"a", "b"},
+		Attrs:   map[string]int{"x": 1, "y": 300},
+		Raw:     []byte{0, 1, 0xff},
+		Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Child:   &codecTestItem{Name: "child"},
+		Renamed: 65535,
+	}
+
+	codecs := map[string]Codec{
+		"json":    JSONCodec{},
+		"gob":     GobCodec{},
+		"msgpack": MsgpackCodec{},
+	}
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			data, err := codec.Marshal(value)
+			if err != nil {
+				t.Fatalf("序列化失败: %v", err)
+			}
+			var got codecTestItem
+			if err := codec.Unmarshal(data, &got); err != nil {
+				t.Fatalf("反序列化失败: %v", err)
+			}
+			if !reflect.DeepEqual(got, value) {
+				t.Errorf("期望值 %+v, 实际值 %+v", value, got)
+			}
+		})
+	}
+}
+
+func TestMsgpackCodec_Format(t *testing.T) {
+	cases := []struct {
+		value any
+		want  []byte
+	}{
+		{nil, []byte{0xc0}},
+		{true, []byte{0xc3}},
+		{1, []byte{0x01}},
+		{-1, []byte{0xff}},
+		{200, []byte{0xcc, 0xc8}},
+		{-200, []byte{0xd1, 0xff, 0x38}},
+		{"ab", []byte{0xa2, 'a', 'b'}},
+		{[]int{1, 2}, []byte{0x92, 0x01, 0x02}},
+		{map[string]int{"b": 2, "a": 1}, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02}},
+		{[]byte{1}, []byte{0xc4, 0x01, 0x01}},
+		{1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+	}
+	for _, c := range cases {
+		got, err := MsgpackCodec{}.Marshal(c.value)
+		if err != nil {
+			t.Fatalf("序列化 %v 失败: %v", c.value, err)
+		}
+		if !bytes.Equal(got, c.want) {
+			t.Errorf("序列化 %v 期望 % x, 实际 % x", c.value, c.want, got)
+		}
+	}
+
+	var generic any
+	data, _ := MsgpackCodec{}.Marshal(map[string]any{"list": []any{int64(1), "x"}, "n": nil})
+	if err := (MsgpackCodec{}).Unmarshal(data, &generic); err != nil {
+		t.Fatalf("反序列化失败: %v", err)
+	}
+	want := map[string]any{"list": []any{int64(1), "x"}, "n": nil}
+	if !reflect.DeepEqual(generic, want) {
+		t.Errorf("期望值 %#v, 实际值 %#v", want, generic)
+	}
+
+	if _, err := (MsgpackCodec{}).Marshal(make(chan int)); err == nil {
+		t.Error("期望不支持的类型序列化失败")
+	}
+	var n int8
+	data, _ = MsgpackCodec{}.Marshal(1000)
+	if err := (MsgpackCodec{}).Unmarshal(data, &n); err == nil {
+		t.Error("期望溢出时反序列化失败")
+	}
+}
+
+type msgpackNode struct {
+	Name string
+	Next *msgpackNode
+}
+
+func TestMsgpackCodec_Cycle(t *testing.T) {
+	node := &msgpackNode{Name: "a"}
+	node.Next = node
+	loop := []any{nil}
+	loop[0] = loop
+	for name, v := range map[string]any{"pointer": node, "slice": loop} {
+		if _, err := (MsgpackCodec{}).Marshal(v); err == nil {
+			t.Errorf("%s: 期望循环引用序列化失败", name)
+		}
+	}
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{Codec: MsgpackCodec{}})
+	if err := cache.Set("k", node, 0); !errors.Is(err, ErrSerialization) {
+		t.Errorf("期望Set返回ErrSerialization, 实际 %v", err)
+	}
+
+	// 同一个指针出现多次但没有循环时正常编码
+	shared := &msgpackNode{Name: "s"}
+	if _, err := (MsgpackCodec{}).Marshal([]*msgpackNode{shared, shared}); err != nil {
+		t.Errorf("期望共享的指针正常编码, 实际 %v", err)
+	}
+}
+
+func TestMsgpackCodec_MalformedLength(t *testing.T) {
+	var ints []int
+	var m map[string]int
+	var generic any
+	var anyKeys map[any]int
+	for name, c := range map[string]struct {
+		data []byte
+		v    any
+	}{
+		"array32":  {[]byte{0xdd, 0x7f, 0xff, 0xff, 0xff}, &ints},
+		"map32":    {[]byte{0xdf, 0x7f, 0xff, 0xff, 0xff}, &m},
+		"generic":  {[]byte{0xdd, 0x7f, 0xff, 0xff, 0xff}, &generic},
+		"fixmap":   {[]byte{0x81, 0x01}, &m},
+		"arraykey": {[]byte{0x81, 0x90, 0x00}, &generic},
+		"typedkey": {[]byte{0x81, 0x80, 0x00}, &anyKeys},
+		"nested":   {append(bytes.Repeat([]byte{0x91}, msgpackMaxDepth+1), 0x01), nil},
+	} {
+		v := c.v
+		if v == nil {
+			v = &generic
+		}
+		if err := (MsgpackCodec{}).Unmarshal(c.data, v); err == nil {
+			t.Errorf("%s: 期望损坏的数据反序列化失败", name)
+		}
+	}
+}
+
+func TestProtoCodec(t *testing.T) {
+	data, err := ProtoCodec{}.Marshal(&protoTestMessage{payload: "hello"})
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	var got protoTestMessage
+	if err := (ProtoCodec{}).Unmarshal(data, &got); err != nil || got.payload != "hello" {
+		t.Errorf("反序列化失败: %v %+v", err, got)
+	}
+
+	if _, err := (ProtoCodec{}).Marshal(1); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("期望返回ErrInvalidParameter, 实际: %v", err)
+	}
+}
+
+func TestCodec_SetSurfacesError(t *testing.T) {
+	defer Init()()
+	fileCache, err := NewFileCache(testFilePath)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	caches := map[string]Cache{
+		"memory":  NewMemoryCache(),
+		"file":    fileCache,
+		"disklog": newDiskLogTestCache(t, nil),
+		"multi":   NewMultiCache(NewMemoryCache()),
+	}
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			err := cache.Set("chan", make(chan int), 0)
+			if !errors.Is(err, ErrSerialization) {
+				t.Errorf("期望返回ErrSerialization, 实际: %v", err)
+			}
+			if exists, _ := cache.Exists("chan"); exists {
+				t.Error("序列化失败的值不应被写入")
+			}
+		})
+	}
+}
+
+func TestCodec_CacheConfig(t *testing.T) {
+	defer Init()()
+	for _, cacheType := range []CacheType{MemoryCacheType, FileCacheType} {
+		t.Run(string(cacheType), func(t *testing.T) {
+			cache, err := NewCache(CacheConfig{Type: cacheType, FileDir: testFilePath, Codec: GobCodec{}})
+			if err != nil {
+				t.Fatalf("创建缓存失败: %v", err)
+			}
+			defer cache.Close()
+
+			value := codecTestItem{Name: "gob", Raw: []byte{0xff, 0xfe}, Created: time.Unix(100, 0).UTC()}
+			if err := cache.Set("gob_key", value, time.Minute); err != nil {
+				t.Fatalf("设置键值对失败: %v", err)
+			}
+			// 未指定codec时TypedCache沿用缓存的Codec
+			got, err := NewTypedCache[codecTestItem](cache, nil).Get("gob_key")
+			if err != nil {
+				t.Fatalf("获取键值对失败: %v", err)
+			}
+			if !reflect.DeepEqual(got, value) {
+				t.Errorf("期望值 %+v, 实际值 %+v", value, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package go_cache
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConditionalCache(t *testing.T) {
+	defer Init()()
+	fileCache, err := NewFileCache(testFilePath)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	caches := map[string]ConditionalCache{
+		"redis":   redisServer,
+		"memory":  NewMemoryCache(),
+		"arena":   newArenaTestCache(),
+		"file":    fileCache,
+		"disklog": newDiskLogTestCache(t, nil),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			c := cache.(Cache)
+			defer c.Delete("cond_key")
+
+			ok, err := cache.SetXX("cond_key", "v0", time.Minute)
+			if err != nil || ok {
+				t.Errorf("期望键不存在时SetXX不写入, 实际: %v %v", ok, err)
+			}
+			ok, err = cache.SetNX("cond_key", "v1", time.Minute)
+			if err != nil || !ok {
+				t.Errorf("期望键不存在时SetNX写入, 实际: %v %v", ok, err)
+			}
+			ok, err = cache.SetNX("cond_key", "v2", time.Minute)
+			if err != nil || ok {
+				t.Errorf("期望键存在时SetNX不写入, 实际: %v %v", ok, err)
+			}
+			ok, err = cache.SetXX("cond_key", "v3", time.Minute)
+			if err != nil || !ok {
+				t.Errorf("期望键存在时SetXX写入, 实际: %v %v", ok, err)
+			}
+
+			ok, err = cache.CompareAndSwap("cond_key", "v1", "v4", time.Minute)
+			if err != nil || ok {
+				t.Errorf("期望旧值不匹配时不写入, 实际: %v %v", ok, err)
+			}
+			ok, err = cache.CompareAndSwap("cond_key", "v3", "v4", time.Minute)
+			if err != nil || !ok {
+				t.Errorf("期望旧值匹配时写入, 实际: %v %v", ok, err)
+			}
+			if got, _ := c.Get("cond_key"); got != "v4" {
+				t.Errorf("期望值 v4, 实际值 %s", got)
+			}
+
+			// 并发SetNX只有一个成功
+			_ = c.Delete("cond_key")
+			var wins int32
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					if ok, _ := cache.SetNX("cond_key", i, time.Minute); ok {
+						atomic.AddInt32(&wins, 1)
+					}
+				}(i)
+			}
+			wg.Wait()
+			if wins != 1 {
+				t.Errorf("期望只有1个SetNX成功, 实际: %d", wins)
+			}
+		})
+	}
+}
+
+func TestConditionalCache_CompareAndSwapLoop(t *testing.T) {
+	defer Init()()
+	fileCache, _ := NewFileCache(testFilePath)
+	for name, cache := range map[string]ConditionalCache{"memory": NewMemoryCache(), "file": fileCache} {
+		t.Run(name, func(t *testing.T) {
+			c := cache.(Cache)
+			_ = c.Set("cas_counter", 0, 0)
+
+			// 乐观更新：读取后CAS，失败重试
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 10; j++ {
+						for {
+							cur, _ := c.Get("cas_counter")
+							n, _ := strconv.Atoi(cur)
+							if ok, err := cache.CompareAndSwap("cas_counter", cur, n+1, 0); err != nil || ok {
+								break
+							}
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			if got, _ := c.Get("cas_counter"); got != "100" {
+				t.Errorf("期望值 100, 实际值 %s", got)
+			}
+		})
+	}
+}
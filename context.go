@@ -0,0 +1,107 @@
+package go_cache
+
+import (
+	"context"
+	"time"
+)
+
+// contextCacheAdapter 将ContextCache适配为Cache，所有调用使用context.Background()
+type contextCacheAdapter struct {
+	cache ContextCache
+}
+
+// NewCacheFromContext 将ContextCache包装为旧的Cache接口
+func NewCacheFromContext(cache ContextCache) Cache {
+	if c, ok := cache.(Cache); ok {
+		return c
+	}
+	return &contextCacheAdapter{cache: cache}
+}
+
+func (a *contextCacheAdapter) Set(key string, value interface{}, expiration time.Duration) error {
+	return a.cache.SetCtx(context.Background(), key, value, expiration)
+}
+
+func (a *contextCacheAdapter) Get(key string) (string, error) {
+	return a.cache.GetCtx(context.Background(), key)
+}
+
+func (a *contextCacheAdapter) Delete(key string) error {
+	return a.cache.DeleteCtx(context.Background(), key)
+}
+
+func (a *contextCacheAdapter) Exists(key string) (bool, error) {
+	return a.cache.ExistsCtx(context.Background(), key)
+}
+
+func (a *contextCacheAdapter) Expire(key string, expiration time.Duration) error {
+	return a.cache.ExpireCtx(context.Background(), key, expiration)
+}
+
+func (a *contextCacheAdapter) TTL(key string) (time.Duration, error) {
+	return a.cache.TTLCtx(context.Background(), key)
+}
+
+func (a *contextCacheAdapter) Close() error {
+	return a.cache.Close()
+}
+
+// cacheContextAdapter 将不支持context的Cache适配为ContextCache，
+// 每次调用前检查ctx是否已取消
+type cacheContextAdapter struct {
+	cache Cache
+}
+
+// WithContext 将Cache包装为ContextCache，如果cache本身已实现ContextCache则直接返回
+func WithContext(cache Cache) ContextCache {
+	if c, ok := cache.(ContextCache); ok {
+		return c
+	}
+	return &cacheContextAdapter{cache: cache}
+}
+
+func (a *cacheContextAdapter) SetCtx(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.cache.Set(key, value, expiration)
+}
+
+func (a *cacheContextAdapter) GetCtx(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return a.cache.Get(key)
+}
+
+func (a *cacheContextAdapter) DeleteCtx(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.cache.Delete(key)
+}
+
+func (a *cacheContextAdapter) ExistsCtx(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return a.cache.Exists(key)
+}
+
+func (a *cacheContextAdapter) ExpireCtx(ctx context.Context, key string, expiration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.cache.Expire(key, expiration)
+}
+
+func (a *cacheContextAdapter) TTLCtx(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return a.cache.TTL(key)
+}
+
+func (a *cacheContextAdapter) Close() error {
+	return a.cache.Close()
+}
@@ -0,0 +1,75 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestContextCache_Canceled(t *testing.T) {
+	defer Init()()
+	fileCache, err := NewFileCache(testFilePath)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	caches := map[string]ContextCache{
+		"memory":  NewMemoryCache(),
+		"file":    fileCache,
+		"disklog": newDiskLogTestCache(t, nil),
+		"multi":   NewMultiCache(NewMemoryCache(), fileCache),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			defer cache.Close()
+			ctx, cancel := context.WithCancel(context.Background())
+			if err := cache.SetCtx(ctx, "ctx_key", "ctx_value", 5*time.Second); err != nil {
+				t.Fatalf("设置键值对失败: %v", err)
+			}
+			got, err := cache.GetCtx(ctx, "ctx_key")
+			if err != nil || got != "ctx_value" {
+				t.Fatalf("获取键值对失败: %v %s", err, got)
+			}
+
+			cancel()
+			if err := cache.SetCtx(ctx, "ctx_key", "v2", 0); !errors.Is(err, context.Canceled) {
+				t.Errorf("期望SetCtx返回context.Canceled, 实际: %v", err)
+			}
+			if _, err := cache.GetCtx(ctx, "ctx_key"); !errors.Is(err, context.Canceled) {
+				t.Errorf("期望GetCtx返回context.Canceled, 实际: %v", err)
+			}
+			if _, err := cache.TTLCtx(ctx, "ctx_key"); !errors.Is(err, context.Canceled) {
+				t.Errorf("期望TTLCtx返回context.Canceled, 实际: %v", err)
+			}
+			if err := cache.DeleteCtx(ctx, "ctx_key"); !errors.Is(err, context.Canceled) {
+				t.Errorf("期望DeleteCtx返回context.Canceled, 实际: %v", err)
+			}
+		})
+	}
+}
+
+func TestContextCache_Adapter(t *testing.T) {
+	memoryCache := NewMemoryCache()
+	cache := NewCacheFromContext(&cacheContextAdapter{cache: memoryCache})
+	defer cache.Close()
+
+	if err := cache.Set("adapter_key", "adapter_value", 0); err != nil {
+		t.Fatalf("设置键值对失败: %v", err)
+	}
+	got, err := memoryCache.Get("adapter_key")
+	if err != nil || got != "adapter_value" {
+		t.Fatalf("获取键值对失败: %v %s", err, got)
+	}
+	ttl, err := cache.TTL("adapter_key")
+	if err != nil || ttl != -1 {
+		t.Errorf("期望TTL为-1, 实际: %v %v", ttl, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	if _, err := WithContext(&contextCacheAdapter{cache: memoryCache}).GetCtx(ctx, "adapter_key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望返回context.DeadlineExceeded, 实际: %v", err)
+	}
+}
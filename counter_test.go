@@ -0,0 +1,117 @@
+package go_cache
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCounterCache(t *testing.T) {
+	defer Init()()
+	fileCache, err := NewFileCache(testFilePath)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	caches := map[string]CounterCache{
+		"redis":   redisServer,
+		"memory":  NewMemoryCache(),
+		"arena":   newArenaTestCache(),
+		"file":    fileCache,
+		"disklog": newDiskLogTestCache(t, nil),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			c := cache.(Cache)
+			defer c.Delete("counter")
+			defer c.Delete("not_number")
+
+			// 并发自增不丢失更新
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 10; j++ {
+						if _, err := cache.Incr("counter", time.Minute); err != nil {
+							t.Errorf("自增失败: %v", err)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+
+			n, err := cache.IncrBy("counter", 5, 0)
+			if err != nil || n != 205 {
+				t.Errorf("期望值 205, 实际值 %d %v", n, err)
+			}
+			n, err = cache.DecrBy("counter", 10, 0)
+			if err != nil || n != 195 {
+				t.Errorf("期望值 195, 实际值 %d %v", n, err)
+			}
+			n, err = cache.Decr("counter", 0)
+			if err != nil || n != 194 {
+				t.Errorf("期望值 194, 实际值 %d %v", n, err)
+			}
+
+			// 过期时间只在创建时设置
+			ttl, err := c.TTL("counter")
+			if err != nil || ttl <= 0 || ttl > time.Minute {
+				t.Errorf("期望保留创建时的过期时间, 实际: %v %v", ttl, err)
+			}
+			if got, _ := c.Get("counter"); got != "194" {
+				t.Errorf("期望值 194, 实际值 %s", got)
+			}
+
+			_ = c.Set("not_number", "abc", 0)
+			if _, err := cache.Incr("not_number", 0); !errors.Is(err, ErrNotInteger) {
+				t.Errorf("期望返回ErrNotInteger, 实际: %v", err)
+			}
+			_ = c.Set("not_number", math.MaxInt64, 0)
+			if _, err := cache.Incr("not_number", 0); !errors.Is(err, ErrNotInteger) {
+				t.Errorf("期望溢出时返回ErrNotInteger, 实际: %v", err)
+			}
+
+			// 不足1毫秒的过期时间不会变成永不过期
+			defer c.Delete("short")
+			_, _ = cache.Incr("short", 500*time.Microsecond)
+			time.Sleep(5 * time.Millisecond)
+			if _, err := c.Get("short"); !errors.Is(err, ErrKeyNotFound) {
+				t.Errorf("期望不足1毫秒的计数器已过期, 实际: %v", err)
+			}
+		})
+	}
+}
+
+func TestCounterCache_ExpiredRestart(t *testing.T) {
+	cache := NewMemoryCache()
+	defer cache.Close()
+
+	_, _ = cache.IncrBy("counter", 10, 50*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	n, err := cache.Incr("counter", 0)
+	if err != nil || n != 1 {
+		t.Errorf("期望过期后重新计数, 实际: %d %v", n, err)
+	}
+	if ttl, _ := cache.TTL("counter"); ttl != -1 {
+		t.Errorf("期望新计数器永不过期, 实际: %v", ttl)
+	}
+}
+
+func TestRedisMillis(t *testing.T) {
+	for d, want := range map[time.Duration]int64{
+		0:                       0,
+		-time.Second:            0,
+		time.Nanosecond:         1,
+		500 * time.Microsecond:  1,
+		time.Millisecond:        1,
+		1500 * time.Microsecond: 2,
+		time.Second:             1000,
+	} {
+		if got := redisMillis(d); got != want {
+			t.Errorf("期望 %v 转换为 %d 毫秒, 实际 %d", d, want, got)
+		}
+	}
+}
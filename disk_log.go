@@ -0,0 +1,986 @@
+package go_cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiskLogCache 实现了基于追加写日志的磁盘缓存：所有写入依次追加到目录下的段文件，
+// 内存中的哈希索引记录每个键最新一条记录的位置，读取时按位置读出值。
+// 与FileCache相比不会为每个键创建一个文件，适合键数量很多的场景；同一目录只能被一个实例打开
+type DiskLogCache struct {
+	dir          string
+	codec        Codec
+	clock        Clock
+	durable      bool
+	segmentSize  int64
+	compactRatio float64
+	notifier     *evictNotifier
+	compactMu    sync.Mutex // 串行化合并与Clear，持有期间编号小于当前段的段不会被删除
+
+	mu       sync.RWMutex
+	index    map[string]*diskLogEntry
+	tags     map[string]map[string]struct{} // 标签到键的索引
+	segments map[uint32]*diskLogSegment
+	active   *diskLogSegment // 当前追加写入的段，编号最大
+	lock     *os.File        // 目录锁文件，防止多个实例同时写入
+	closed   bool
+	stop     func() // 停止后台合并
+}
+
+// DiskLogCacheOptions 日志缓存的可选配置
+type DiskLogCacheOptions struct {
+	// Codec 非字符串值的序列化方式，默认为JSONCodec
+	Codec Codec
+
+	// Clock 读取当前时间的时钟，默认为系统时钟，测试时可以使用FakeClock
+	Clock Clock
+
+	// Durable 为true时每次写入后fsync当前段，保证断电后写入仍然有效，代价是更慢的写入。
+	// 无论是否开启，进程崩溃都不会丢失已返回的写入
+	Durable bool
+
+	// SegmentSize 单个段文件的大小上限，超过后新建段，默认为64MB
+	SegmentSize int64
+
+	// CompactInterval 后台检查是否需要合并的间隔，0表示不启动后台合并，只在调用Compact时合并
+	CompactInterval time.Duration
+
+	// CompactRatio 无效记录（已覆盖、删除或过期）的字节数占比达到该值时后台合并，默认为0.5
+	CompactRatio float64
+}
+
+// diskLogDefaultSegmentSize 默认的段文件大小上限
+const diskLogDefaultSegmentSize = 64 << 20
+
+// diskLogDefaultCompactRatio 默认触发后台合并的无效记录占比
+const diskLogDefaultCompactRatio = 0.5
+
+// diskLogLockFile 目录锁文件的名称
+const diskLogLockFile = "LOCK"
+
+// diskLogEntry 索引中一个键的最新记录
+type diskLogEntry struct {
+	segment    uint32
+	offset     int64
+	size       int64
+	expiration time.Time
+	sliding    time.Duration
+	tags       []string
+}
+
+// expired 判断记录在now时是否已过期
+func (e *diskLogEntry) expired(now time.Time) bool {
+	return !e.expiration.IsZero() && now.After(e.expiration)
+}
+
+// diskLogSegment 一个段文件
+type diskLogSegment struct {
+	id   uint32
+	file *os.File
+	size int64
+	dead int64 // 已被覆盖、删除或过期的记录的字节数，合并时回收
+}
+
+// NewDiskLogCache 创建一个新的日志缓存实例
+func NewDiskLogCache(dir string) (*DiskLogCache, error) {
+	return NewDiskLogCacheWithOptions(dir, DiskLogCacheOptions{})
+}
+
+// NewDiskLogCacheWithOptions 使用指定配置创建一个新的日志缓存实例，依次重放目录中已有的段文件重建索引。
+// 最后一个段末尾写入时崩溃残留的不完整记录会被截断，其他段中的损坏记录返回ErrCorrupted
+func NewDiskLogCacheWithOptions(dir string, opts DiskLogCacheOptions) (*DiskLogCache, error) {
+	if opts.SegmentSize < 0 || opts.CompactRatio < 0 || opts.CompactRatio > 1 {
+		return nil, fmt.Errorf("%w: negative segment size or compact ratio out of [0, 1]", ErrInvalidParameter)
+	}
+	if opts.Codec == nil {
+		opts.Codec = JSONCodec{}
+	}
+	if opts.SegmentSize == 0 {
+		opts.SegmentSize = diskLogDefaultSegmentSize
+	}
+	if opts.CompactRatio == 0 {
+		opts.CompactRatio = diskLogDefaultCompactRatio
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	lock, err := os.OpenFile(filepath.Join(dir, diskLogLockFile), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := tryLockFile(lock)
+	if err == nil && !ok {
+		err = fmt.Errorf("%w: %s", ErrLocked, dir)
+	}
+	if err != nil {
+		lock.Close()
+		return nil, err
+	}
+
+	cache := &DiskLogCache{
+		dir:          dir,
+		codec:        opts.Codec,
+		clock:        clockOrSystem(opts.Clock),
+		durable:      opts.Durable,
+		segmentSize:  opts.SegmentSize,
+		compactRatio: opts.CompactRatio,
+		notifier:     &evictNotifier{},
+		index:        make(map[string]*diskLogEntry),
+		tags:         make(map[string]map[string]struct{}),
+		segments:     make(map[uint32]*diskLogSegment),
+		lock:         lock,
+		stop:         func() {},
+	}
+	if err := cache.open(); err != nil {
+		cache.closeFiles()
+		return nil, err
+	}
+	if opts.CompactInterval > 0 {
+		cache.stop = cache.clock.Every(opts.CompactInterval, func() {
+			_ = cache.compactIfNeeded()
+		})
+	}
+	return cache, nil
+}
+
+// segmentPath 返回段文件的路径
+func (d *DiskLogCache) segmentPath(id uint32) string {
+	return filepath.Join(d.dir, fmt.Sprintf("%010d.seg", id))
+}
+
+// open 删除合并时崩溃残留的临时文件，按编号依次重放所有段文件，没有段文件时新建第一个段
+func (d *DiskLogCache) open() error {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+	var ids []uint32
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.Contains(name, ".tmp-") {
+			if err := os.Remove(filepath.Join(d.dir, name)); err != nil {
+				return err
+			}
+			continue
+		}
+		idStr, ok := strings.CutSuffix(name, ".seg")
+		if !ok {
+			continue
+		}
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		ids = append(ids, uint32(id))
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	now := d.clock.Now()
+	for i, id := range ids {
+		file, err := os.OpenFile(d.segmentPath(id), os.O_RDWR, 0644)
+		if err != nil {
+			return err
+		}
+		seg := &diskLogSegment{id: id, file: file}
+		d.segments[id] = seg
+		d.active = seg
+		if err := d.replay(seg, i == len(ids)-1, now); err != nil {
+			return err
+		}
+	}
+	if d.active == nil {
+		_, err = d.newSegmentLocked(1)
+	}
+	return err
+}
+
+// replay 重放一个段文件中的所有记录。last为true时把末尾不完整或损坏的记录视为写入时崩溃残留并截断
+func (d *DiskLogCache) replay(seg *diskLogSegment, last bool, now time.Time) error {
+	info, err := seg.file.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+	r := bufio.NewReader(io.NewSectionReader(seg.file, 0, size))
+	var offset int64
+	for offset < size {
+		data, err := readDiskLogRecord(r, size-offset)
+		var op byte
+		var item *fileItem
+		if err == nil {
+			op, item, err = decodeDiskLogRecord(data)
+		}
+		if err != nil {
+			if !last {
+				return fmt.Errorf("%w: segment %d at offset %d: %w", ErrCorrupted, seg.id, offset, err)
+			}
+			if err := seg.file.Truncate(offset); err != nil {
+				return err
+			}
+			break
+		}
+
+		n := int64(len(data))
+		switch op {
+		case diskLogOpCompacted:
+			if offset == 0 {
+				// 合并或Clear在删除旧段之前崩溃，旧段已被该段取代
+				if err := d.dropSegmentsBefore(seg.id); err != nil {
+					return err
+				}
+			}
+		case diskLogOpDelete:
+			if old := d.index[item.Key]; old != nil {
+				d.retireLocked(item.Key, old)
+			}
+			seg.dead += n
+		case diskLogOpPut:
+			entry := newDiskLogEntry(seg.id, offset, n, item)
+			if entry.expired(now) {
+				if old := d.index[item.Key]; old != nil {
+					d.retireLocked(item.Key, old)
+				}
+				seg.dead += n
+			} else {
+				d.replaceLocked(item.Key, entry)
+			}
+		}
+		offset += n
+	}
+	seg.size = offset
+	return nil
+}
+
+// dropSegmentsBefore 关闭并删除编号小于id的段，清空索引
+func (d *DiskLogCache) dropSegmentsBefore(id uint32) error {
+	for segID, seg := range d.segments {
+		if segID >= id {
+			continue
+		}
+		seg.file.Close()
+		delete(d.segments, segID)
+		if err := os.Remove(d.segmentPath(segID)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	d.index = make(map[string]*diskLogEntry)
+	d.tags = make(map[string]map[string]struct{})
+	return nil
+}
+
+// newDiskLogEntry 根据写入的记录创建索引项
+func newDiskLogEntry(segment uint32, offset, size int64, item *fileItem) *diskLogEntry {
+	return &diskLogEntry{
+		segment:    segment,
+		offset:     offset,
+		size:       size,
+		expiration: item.Expiration,
+		sliding:    item.Sliding,
+		tags:       item.Tags,
+	}
+}
+
+// newSegmentLocked 新建编号为id的空段并设为当前段
+func (d *DiskLogCache) newSegmentLocked(id uint32) (*diskLogSegment, error) {
+	file, err := os.OpenFile(d.segmentPath(id), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if d.durable {
+		if err := syncDir(d.dir); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	seg := &diskLogSegment{id: id, file: file}
+	d.segments[id] = seg
+	d.active = seg
+	return seg, nil
+}
+
+// appendLocked 将记录追加到当前段，当前段超过大小上限时先新建段
+func (d *DiskLogCache) appendLocked(op byte, item *fileItem) (*diskLogEntry, error) {
+	if d.active.size >= d.segmentSize {
+		if _, err := d.newSegmentLocked(d.active.id + 1); err != nil {
+			return nil, err
+		}
+	}
+	seg := d.active
+	data := encodeDiskLogRecord(op, item)
+	if _, err := seg.file.WriteAt(data, seg.size); err != nil {
+		// 丢弃只写入了一部分的记录，保持段文件完整
+		_ = seg.file.Truncate(seg.size)
+		return nil, err
+	}
+	if d.durable {
+		if err := seg.file.Sync(); err != nil {
+			return nil, err
+		}
+	}
+	entry := newDiskLogEntry(seg.id, seg.size, int64(len(data)), item)
+	seg.size += entry.size
+	return entry, nil
+}
+
+// putLocked 追加写入记录并更新索引，旧值以reason通知；旧值已过期时以EvictReasonExpired通知
+func (d *DiskLogCache) putLocked(item *fileItem, reason EvictReason, ev *evictEvents) error {
+	old := d.liveLocked(item.Key, ev)
+	if old != nil && ev.enabled() {
+		if oldItem, err := d.readLocked(old); err == nil {
+			ev.add(item.Key, oldItem.value(), reason)
+		}
+	}
+	entry, err := d.appendLocked(diskLogOpPut, item)
+	if err != nil {
+		return err
+	}
+	d.replaceLocked(item.Key, entry)
+	return nil
+}
+
+// deleteLocked 追加删除记录并从索引中删除键，已删除的值以reason通知
+func (d *DiskLogCache) deleteLocked(key string, reason EvictReason, ev *evictEvents) error {
+	entry := d.liveLocked(key, ev)
+	if entry == nil {
+		return nil
+	}
+	var value string
+	if ev.enabled() {
+		if item, err := d.readLocked(entry); err == nil {
+			value = item.value()
+		}
+	}
+	tombstone, err := d.appendLocked(diskLogOpDelete, &fileItem{Key: key})
+	if err != nil {
+		return err
+	}
+	d.segments[tombstone.segment].dead += tombstone.size
+	d.retireLocked(key, entry)
+	ev.add(key, value, reason)
+	return nil
+}
+
+// replaceLocked 将键的索引项替换为entry，旧的记录计入所在段的无效字节数
+func (d *DiskLogCache) replaceLocked(key string, entry *diskLogEntry) {
+	if old := d.index[key]; old != nil {
+		d.retireLocked(key, old)
+	}
+	d.index[key] = entry
+	for _, tag := range entry.tags {
+		keys := d.tags[tag]
+		if keys == nil {
+			keys = make(map[string]struct{})
+			d.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// retireLocked 从索引中删除键，记录计入所在段的无效字节数
+func (d *DiskLogCache) retireLocked(key string, entry *diskLogEntry) {
+	d.segments[entry.segment].dead += entry.size
+	for _, tag := range entry.tags {
+		if keys := d.tags[tag]; keys != nil {
+			delete(keys, key)
+			if len(keys) == 0 {
+				delete(d.tags, tag)
+			}
+		}
+	}
+	delete(d.index, key)
+}
+
+// liveLocked 返回键未过期的索引项；键已过期时从索引中删除并以EvictReasonExpired通知。
+// 过期的记录不需要删除记录，重建索引时会再次判断为过期
+func (d *DiskLogCache) liveLocked(key string, ev *evictEvents) *diskLogEntry {
+	entry := d.index[key]
+	if entry == nil || !entry.expired(d.clock.Now()) {
+		return entry
+	}
+	if ev.enabled() {
+		if item, err := d.readLocked(entry); err == nil {
+			ev.add(key, item.value(), EvictReasonExpired)
+		}
+	}
+	d.retireLocked(key, entry)
+	return nil
+}
+
+// readLocked 读出索引项指向的记录，调用方至少持有读锁
+func (d *DiskLogCache) readLocked(entry *diskLogEntry) (*fileItem, error) {
+	data := make([]byte, entry.size)
+	if _, err := d.segments[entry.segment].file.ReadAt(data, entry.offset); err != nil {
+		return nil, err
+	}
+	_, item, err := decodeDiskLogRecord(data)
+	return item, err
+}
+
+// view 在读锁内执行fn。只读的操作遇到已过期的键时，释放读锁后再用update从索引中删除
+func (d *DiskLogCache) view(fn func() error) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
+	return fn()
+}
+
+// update 在写锁内执行fn，结束后通知移除事件
+func (d *DiskLogCache) update(fn func(ev *evictEvents) error) error {
+	ev := d.notifier.collect()
+	defer ev.flush()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return ErrClosed
+	}
+	return fn(ev)
+}
+
+// Set 将键值对存储到缓存中，并设置过期时间
+func (d *DiskLogCache) Set(key string, value interface{}, expiration time.Duration) error {
+	str, err := encodeValue(d.codec, value)
+	if err != nil {
+		return err
+	}
+	return d.update(func(ev *evictEvents) error {
+		return d.putLocked(newFileItem(key, str, expireAt(d.clock.Now(), expiration)), EvictReasonReplaced, ev)
+	})
+}
+
+// Get 从缓存中获取指定键的值。只有键已过期或滑动过期的键需要追加续期记录时才加写锁
+func (d *DiskLogCache) Get(key string) (string, error) {
+	var value string
+	upgrade := false
+	err := d.view(func() error {
+		entry := d.index[key]
+		if entry == nil {
+			return ErrKeyNotFound
+		}
+		now := d.clock.Now()
+		if entry.expired(now) || (entry.sliding > 0 && needsTouch(entry.expiration, expireAt(now, entry.sliding), entry.sliding)) {
+			upgrade = true
+			return nil
+		}
+		item, err := d.readLocked(entry)
+		if err != nil {
+			return err
+		}
+		value = item.value()
+		return nil
+	})
+	if err != nil || !upgrade {
+		return value, err
+	}
+
+	// 已过期的键需要从索引中删除，滑动过期的键需要续期
+	err = d.update(func(ev *evictEvents) error {
+		item, err := d.touchLocked(key, ev, func(entry *diskLogEntry) (time.Duration, bool) {
+			return entry.sliding, entry.sliding > 0
+		})
+		if err == nil {
+			value = item.value()
+		}
+		return err
+	})
+	return value, err
+}
+
+// GetAndTouch 获取指定键的值，并将过期时间重置为ttl，ttl<=0表示永不过期
+func (d *DiskLogCache) GetAndTouch(key string, ttl time.Duration) (string, error) {
+	var value string
+	err := d.update(func(ev *evictEvents) error {
+		item, err := d.touchLocked(key, ev, func(*diskLogEntry) (time.Duration, bool) {
+			return ttl, true
+		})
+		if err == nil {
+			value = item.value()
+		}
+		return err
+	})
+	return value, err
+}
+
+// SetSliding 写入滑动过期的键值对，之后每次Get或GetMulti命中都将过期时间重置为读取时刻加ttl。
+// 与FileCache相同，过期时间只延长不到ttl/10时不追加记录，因此条目最多可能提前ttl/10过期
+func (d *DiskLogCache) SetSliding(key string, value interface{}, ttl time.Duration) error {
+	str, err := encodeValue(d.codec, value)
+	if err != nil {
+		return err
+	}
+	return d.update(func(ev *evictEvents) error {
+		item := newFileItem(key, str, expireAt(d.clock.Now(), ttl))
+		item.Sliding = max(ttl, 0)
+		return d.putLocked(item, EvictReasonReplaced, ev)
+	})
+}
+
+// touchLocked 读取键的记录，并将过期时间重置为ttl(entry)返回的时长后追加写入，第二个返回值为false时不修改
+func (d *DiskLogCache) touchLocked(key string, ev *evictEvents, ttl func(entry *diskLogEntry) (time.Duration, bool)) (*fileItem, error) {
+	entry := d.liveLocked(key, ev)
+	if entry == nil {
+		return nil, ErrKeyNotFound
+	}
+	item, err := d.readLocked(entry)
+	if err != nil {
+		return nil, err
+	}
+	dur, ok := ttl(entry)
+	if !ok {
+		return item, nil
+	}
+	expiration := expireAt(d.clock.Now(), dur)
+	if !needsTouch(item.Expiration, expiration, dur) {
+		return item, nil
+	}
+	item.Expiration = expiration
+	return item, d.rewriteLocked(item)
+}
+
+// rewriteLocked 追加写入修改后的记录，不通知覆盖事件
+func (d *DiskLogCache) rewriteLocked(item *fileItem) error {
+	entry, err := d.appendLocked(diskLogOpPut, item)
+	if err != nil {
+		return err
+	}
+	d.replaceLocked(item.Key, entry)
+	return nil
+}
+
+// Delete 从缓存中删除指定键
+func (d *DiskLogCache) Delete(key string) error {
+	return d.update(func(ev *evictEvents) error {
+		return d.deleteLocked(key, EvictReasonDeleted, ev)
+	})
+}
+
+// Exists 检查指定键是否存在于缓存中，只在读锁内查询内存中的索引
+func (d *DiskLogCache) Exists(key string) (bool, error) {
+	var exists, expired bool
+	err := d.view(func() error {
+		entry := d.index[key]
+		expired = entry != nil && entry.expired(d.clock.Now())
+		exists = entry != nil && !expired
+		return nil
+	})
+	if expired {
+		d.removeExpired([]string{key})
+	}
+	return exists, err
+}
+
+// Expire 设置键的过期时间
+func (d *DiskLogCache) Expire(key string, expiration time.Duration) error {
+	return d.update(func(ev *evictEvents) error {
+		entry := d.liveLocked(key, ev)
+		if entry == nil {
+			return ErrKeyNotFound
+		}
+		item, err := d.readLocked(entry)
+		if err != nil {
+			return err
+		}
+		item.Expiration = expireAt(d.clock.Now(), expiration)
+		return d.rewriteLocked(item)
+	})
+}
+
+// TTL 获取键的剩余生存时间，只在读锁内查询内存中的索引
+func (d *DiskLogCache) TTL(key string) (time.Duration, error) {
+	var ttl time.Duration
+	expired := false
+	err := d.view(func() error {
+		entry := d.index[key]
+		if entry == nil {
+			return ErrKeyNotFound
+		}
+		now := d.clock.Now()
+		if entry.expired(now) {
+			expired = true
+			return ErrKeyNotFound
+		}
+		if entry.expiration.IsZero() {
+			// 永不过期
+			ttl = -1
+		} else {
+			ttl = entry.expiration.Sub(now)
+		}
+		return nil
+	})
+	if expired {
+		d.removeExpired([]string{key})
+	}
+	return ttl, err
+}
+
+// removeExpired 在写锁内从索引中删除仍然过期的键并通知，供只持有读锁的操作在释放读锁后调用
+func (d *DiskLogCache) removeExpired(keys []string) {
+	_ = d.update(func(ev *evictEvents) error {
+		for _, key := range keys {
+			d.liveLocked(key, ev)
+		}
+		return nil
+	})
+}
+
+// OnEvict 注册监听函数，缓存项因过期、删除或覆盖离开缓存时调用，调用时不持有缓存锁
+func (d *DiskLogCache) OnEvict(fn func(key, value string, reason EvictReason)) {
+	d.notifier.add(fn)
+}
+
+// Codec 返回缓存使用的序列化方式
+func (d *DiskLogCache) Codec() Codec {
+	return d.codec
+}
+
+// Close 停止后台合并，关闭段文件并释放目录锁，之后的操作返回ErrClosed
+func (d *DiskLogCache) Close() error {
+	d.stop()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+	var err error
+	if d.active != nil {
+		err = d.active.file.Sync()
+	}
+	d.closeFiles()
+	return err
+}
+
+// closeFiles 关闭所有段文件和目录锁文件
+func (d *DiskLogCache) closeFiles() {
+	for _, seg := range d.segments {
+		seg.file.Close()
+	}
+	_ = unlockFile(d.lock)
+	d.lock.Close()
+}
+
+// SetCtx 将键值对存储到缓存中，ctx已取消时直接返回
+func (d *DiskLogCache) SetCtx(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return d.Set(key, value, expiration)
+}
+
+// GetCtx 从缓存中获取指定键的值，ctx已取消时直接返回
+func (d *DiskLogCache) GetCtx(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return d.Get(key)
+}
+
+// DeleteCtx 从缓存中删除指定键，ctx已取消时直接返回
+func (d *DiskLogCache) DeleteCtx(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return d.Delete(key)
+}
+
+// ExistsCtx 检查指定键是否存在于缓存中，ctx已取消时直接返回
+func (d *DiskLogCache) ExistsCtx(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return d.Exists(key)
+}
+
+// ExpireCtx 设置键的过期时间，ctx已取消时直接返回
+func (d *DiskLogCache) ExpireCtx(ctx context.Context, key string, expiration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return d.Expire(key, expiration)
+}
+
+// TTLCtx 获取键的剩余生存时间，ctx已取消时直接返回
+func (d *DiskLogCache) TTLCtx(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return d.TTL(key)
+}
+
+// GetMulti 逐个读取多个键，结果中只包含找到的键
+func (d *DiskLogCache) GetMulti(keys []string) (map[string]string, error) {
+	values := make(map[string]string, len(keys))
+	errs := make(map[string]error)
+	for _, key := range keys {
+		value, err := d.Get(key)
+		switch err {
+		case nil:
+			values[key] = value
+		case ErrKeyNotFound:
+		default:
+			errs[key] = err
+		}
+	}
+	return values, newBatchError(errs)
+}
+
+// SetMulti 在一次加锁内依次追加写入多个键值对
+func (d *DiskLogCache) SetMulti(items map[string]interface{}, expiration time.Duration) error {
+	errs := make(map[string]error)
+	encoded := encodeItems(d.codec, items, errs)
+	err := d.update(func(ev *evictEvents) error {
+		expirationTime := expireAt(d.clock.Now(), expiration)
+		for key, str := range encoded {
+			if err := d.putLocked(newFileItem(key, str, expirationTime), EvictReasonReplaced, ev); err != nil {
+				errs[key] = err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return newBatchError(errs)
+}
+
+// DeleteMulti 在一次加锁内依次删除多个键
+func (d *DiskLogCache) DeleteMulti(keys []string) error {
+	errs := make(map[string]error)
+	err := d.update(func(ev *evictEvents) error {
+		for _, key := range keys {
+			if err := d.deleteLocked(key, EvictReasonDeleted, ev); err != nil {
+				errs[key] = err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return newBatchError(errs)
+}
+
+// Incr 将计数器加1
+func (d *DiskLogCache) Incr(key string, expiration time.Duration) (int64, error) {
+	return d.IncrBy(key, 1, expiration)
+}
+
+// Decr 将计数器减1
+func (d *DiskLogCache) Decr(key string, expiration time.Duration) (int64, error) {
+	return d.IncrBy(key, -1, expiration)
+}
+
+// DecrBy 将计数器减delta
+func (d *DiskLogCache) DecrBy(key string, delta int64, expiration time.Duration) (int64, error) {
+	if delta == math.MinInt64 {
+		return 0, ErrNotInteger
+	}
+	return d.IncrBy(key, -delta, expiration)
+}
+
+// IncrBy 在写锁内读取、修改并追加写入计数器，计数器不存在时以expiration创建
+func (d *DiskLogCache) IncrBy(key string, delta int64, expiration time.Duration) (int64, error) {
+	var n int64
+	err := d.update(func(ev *evictEvents) error {
+		entry := d.liveLocked(key, ev)
+		if entry == nil {
+			n = delta
+			return d.rewriteLocked(newFileItem(key, strconv.FormatInt(delta, 10), expireAt(d.clock.Now(), expiration)))
+		}
+		item, err := d.readLocked(entry)
+		if err != nil {
+			return err
+		}
+		old := item.value()
+		if n, err = incrValue(old, delta); err != nil {
+			return err
+		}
+		item.Value, item.Data = strconv.FormatInt(n, 10), nil
+		if err := d.rewriteLocked(item); err != nil {
+			return err
+		}
+		ev.add(key, old, EvictReasonReplaced)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// SetNX 仅当键不存在时写入
+func (d *DiskLogCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return d.setIf(key, value, expiration, func(item *fileItem) bool {
+		return item == nil
+	})
+}
+
+// SetXX 仅当键已存在时写入
+func (d *DiskLogCache) SetXX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return d.setIf(key, value, expiration, func(item *fileItem) bool {
+		return item != nil
+	})
+}
+
+// CompareAndSwap 仅当键的当前值等于old时写入new
+func (d *DiskLogCache) CompareAndSwap(key string, old, new interface{}, expiration time.Duration) (bool, error) {
+	oldStr, err := encodeValue(d.codec, old)
+	if err != nil {
+		return false, err
+	}
+	return d.setIf(key, new, expiration, func(item *fileItem) bool {
+		return item != nil && item.value() == oldStr
+	})
+}
+
+// setIf 在写锁内检查条件，条件满足时写入；键不存在时cond的参数为nil
+func (d *DiskLogCache) setIf(key string, value interface{}, expiration time.Duration, cond func(item *fileItem) bool) (bool, error) {
+	str, err := encodeValue(d.codec, value)
+	if err != nil {
+		return false, err
+	}
+	var ok bool
+	err = d.update(func(ev *evictEvents) error {
+		var item *fileItem
+		if entry := d.liveLocked(key, ev); entry != nil {
+			if item, err = d.readLocked(entry); err != nil {
+				return err
+			}
+		}
+		if !cond(item) {
+			return nil
+		}
+		if err := d.rewriteLocked(newFileItem(key, str, expireAt(d.clock.Now(), expiration))); err != nil {
+			return err
+		}
+		if item != nil {
+			ev.add(key, item.value(), EvictReasonReplaced)
+		}
+		ok = true
+		return nil
+	})
+	return ok, err
+}
+
+// Keys 返回匹配pattern的所有未过期键
+func (d *DiskLogCache) Keys(pattern string) ([]string, error) {
+	return d.keysMatching(func(key string) bool {
+		return matchPattern(pattern, key)
+	})
+}
+
+// Scan 返回以prefix开头的键的迭代器，迭代的是调用时的键快照
+func (d *DiskLogCache) Scan(prefix string) KeyIterator {
+	return newSliceKeyIterator(d.keysMatching(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	}))
+}
+
+// keysMatching 在读锁内收集满足条件的未过期键，遇到的过期键之后在写锁内从索引中删除
+func (d *DiskLogCache) keysMatching(match func(key string) bool) ([]string, error) {
+	var keys, expired []string
+	err := d.view(func() error {
+		now := d.clock.Now()
+		for key, entry := range d.index {
+			if !match(key) {
+				continue
+			}
+			if entry.expired(now) {
+				expired = append(expired, key)
+			} else {
+				keys = append(keys, key)
+			}
+		}
+		return nil
+	})
+	if len(expired) > 0 {
+		d.removeExpired(expired)
+	}
+	return keys, err
+}
+
+// Clear 删除缓存中的所有键。先写入新的段并以合并标记开头，再删除旧的段，中途崩溃时重建索引同样得到空缓存
+func (d *DiskLogCache) Clear() error {
+	d.compactMu.Lock()
+	defer d.compactMu.Unlock()
+	return d.update(func(ev *evictEvents) error {
+		if ev.enabled() {
+			for key := range d.index {
+				if entry := d.liveLocked(key, ev); entry != nil {
+					if item, err := d.readLocked(entry); err == nil {
+						ev.add(key, item.value(), EvictReasonDeleted)
+					}
+				}
+			}
+		}
+
+		if _, err := d.newSegmentLocked(d.active.id + 1); err != nil {
+			return err
+		}
+		if _, err := d.appendLocked(diskLogOpCompacted, &fileItem{}); err != nil {
+			return err
+		}
+		if err := d.active.file.Sync(); err != nil {
+			return err
+		}
+		return d.dropSegmentsBefore(d.active.id)
+	})
+}
+
+// SetWithTags 将键值对存储到缓存中并关联标签，标签与值记录在同一条记录中
+func (d *DiskLogCache) SetWithTags(key string, value interface{}, expiration time.Duration, tags ...string) error {
+	str, err := encodeValue(d.codec, value)
+	if err != nil {
+		return err
+	}
+	return d.update(func(ev *evictEvents) error {
+		item := newFileItem(key, str, expireAt(d.clock.Now(), expiration))
+		item.Tags = uniqueKeys(append([]string(nil), tags...))
+		return d.putLocked(item, EvictReasonReplaced, ev)
+	})
+}
+
+// InvalidateTag 删除关联了tag的所有键
+func (d *DiskLogCache) InvalidateTag(tag string) error {
+	return d.update(func(ev *evictEvents) error {
+		for key := range d.tags[tag] {
+			if err := d.deleteLocked(key, EvictReasonDeleted, ev); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// TagKeys 在读锁内返回关联了tag的所有未过期键，遇到的过期键之后在写锁内从索引中删除
+func (d *DiskLogCache) TagKeys(tag string) ([]string, error) {
+	var keys, expired []string
+	err := d.view(func() error {
+		now := d.clock.Now()
+		for key := range d.tags[tag] {
+			if d.index[key].expired(now) {
+				expired = append(expired, key)
+			} else {
+				keys = append(keys, key)
+			}
+		}
+		return nil
+	})
+	if len(expired) > 0 {
+		d.removeExpired(expired)
+	}
+	return keys, err
+}
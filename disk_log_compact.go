@@ -0,0 +1,219 @@
+package go_cache
+
+import (
+	"bufio"
+	"os"
+	"sort"
+)
+
+// Compact 合并段文件：删除索引中已过期的键，把之前所有段中仍然有效的记录复制到一个新段，再删除旧的段。
+// 新段以合并标记开头，在删除旧段之前崩溃时，重建索引会忽略被取代的旧段。
+// 复制记录时不持有锁，只在开始时和安装新段时短暂持有写锁，期间的读写不会被阻塞
+func (d *DiskLogCache) Compact() error {
+	return d.compact(func() bool { return true })
+}
+
+// compactIfNeeded 删除已过期的键，无效记录的占比达到CompactRatio时合并，由后台定期调用
+func (d *DiskLogCache) compactIfNeeded() error {
+	return d.compact(func() bool {
+		var size, dead int64
+		for _, seg := range d.segments {
+			size += seg.size
+			dead += seg.dead
+		}
+		return dead > 0 && float64(dead) >= float64(size)*d.compactRatio
+	})
+}
+
+// expireLocked 从索引中删除所有已过期的键
+func (d *DiskLogCache) expireLocked(ev *evictEvents) {
+	for key := range d.index {
+		d.liveLocked(key, ev)
+	}
+}
+
+// diskLogCompaction 一次合并开始时的索引快照。编号不大于target的段不再被追加写入，
+// 并且只有持有compactMu时才会被删除，因此复制期间可以不加锁读取
+type diskLogCompaction struct {
+	target  uint32
+	records []diskLogCompactRecord
+	files   map[uint32]*os.File
+}
+
+// diskLogCompactRecord 快照中的一条记录，entry用于安装时判断键在复制期间是否被修改
+type diskLogCompactRecord struct {
+	key     string
+	entry   *diskLogEntry
+	segment uint32
+	offset  int64
+	size    int64
+}
+
+// compact 持有compactMu执行一次合并：在写锁内删除过期键，should返回true时新建当前段并记录快照；
+// 然后不加锁地把快照中的记录复制到临时文件，最后在写锁内安装新段
+func (d *DiskLogCache) compact(should func() bool) error {
+	d.compactMu.Lock()
+	defer d.compactMu.Unlock()
+
+	var plan *diskLogCompaction
+	err := d.update(func(ev *evictEvents) error {
+		d.expireLocked(ev)
+		if !should() {
+			return nil
+		}
+		var err error
+		plan, err = d.planCompactLocked()
+		return err
+	})
+	if err != nil || plan == nil {
+		return err
+	}
+
+	tmp, offsets, size, err := d.writeCompacted(plan)
+	if err != nil {
+		return err
+	}
+	installed := false
+	defer func() {
+		if !installed {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+	return d.update(func(*evictEvents) error {
+		var err error
+		installed, err = d.installCompactedLocked(plan, tmp, offsets, size)
+		return err
+	})
+}
+
+// planCompactLocked 先新建当前段，再记录编号更小的段中所有有效记录，合并后的段使用其中最大的编号。
+// 没有需要合并的段时返回nil
+func (d *DiskLogCache) planCompactLocked() (*diskLogCompaction, error) {
+	if d.active.size > 0 {
+		if _, err := d.newSegmentLocked(d.active.id + 1); err != nil {
+			return nil, err
+		}
+	}
+	plan := &diskLogCompaction{target: d.active.id - 1, files: make(map[uint32]*os.File)}
+	if _, ok := d.segments[plan.target]; !ok {
+		return nil, nil
+	}
+	for id, seg := range d.segments {
+		if id <= plan.target {
+			plan.files[id] = seg.file
+		}
+	}
+	for key, entry := range d.index {
+		if entry.segment <= plan.target {
+			plan.records = append(plan.records, diskLogCompactRecord{
+				key:     key,
+				entry:   entry,
+				segment: entry.segment,
+				offset:  entry.offset,
+				size:    entry.size,
+			})
+		}
+	}
+	// 按段和偏移排序，顺序读取旧的段
+	sort.Slice(plan.records, func(i, j int) bool {
+		a, b := plan.records[i], plan.records[j]
+		if a.segment != b.segment {
+			return a.segment < b.segment
+		}
+		return a.offset < b.offset
+	})
+	return plan, nil
+}
+
+// writeCompacted 把快照中的记录复制到以合并标记开头的临时文件并fsync，不持有锁，
+// 返回临时文件、每条记录在其中的偏移和文件大小
+func (d *DiskLogCache) writeCompacted(plan *diskLogCompaction) (*os.File, []int64, int64, error) {
+	tmp, err := os.CreateTemp(d.dir, "compact.tmp-*")
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	done := false
+	defer func() {
+		if !done {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	w := bufio.NewWriter(tmp)
+	marker := encodeDiskLogRecord(diskLogOpCompacted, &fileItem{})
+	if _, err := w.Write(marker); err != nil {
+		return nil, nil, 0, err
+	}
+	offsets := make([]int64, len(plan.records))
+	size := int64(len(marker))
+	for i, rec := range plan.records {
+		data := make([]byte, rec.size)
+		if _, err := plan.files[rec.segment].ReadAt(data, rec.offset); err != nil {
+			return nil, nil, 0, err
+		}
+		// 校验后原样复制，不把损坏的记录带到新段
+		if _, _, err := decodeDiskLogRecord(data); err != nil {
+			return nil, nil, 0, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, nil, 0, err
+		}
+		offsets[i] = size
+		size += rec.size
+	}
+	if err := w.Flush(); err != nil {
+		return nil, nil, 0, err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		return nil, nil, 0, err
+	}
+	if err := tmp.Sync(); err != nil {
+		return nil, nil, 0, err
+	}
+	done = true
+	return tmp, offsets, size, nil
+}
+
+// installCompactedLocked 把临时文件重命名为合并后的段，替换编号不大于target的段并更新索引。
+// 复制期间被覆盖、删除或过期的键保留其新的索引项，它们在新段中的记录计入无效字节数。
+// 返回值表示临时文件是否已被重命名
+func (d *DiskLogCache) installCompactedLocked(plan *diskLogCompaction, tmp *os.File, offsets []int64, size int64) (bool, error) {
+	// 重命名持久化之后才能删除旧的段
+	if err := os.Rename(tmp.Name(), d.segmentPath(plan.target)); err != nil {
+		return false, err
+	}
+	if err := syncDir(d.dir); err != nil {
+		tmp.Close()
+		return true, err
+	}
+
+	var stale []uint32
+	for id, seg := range d.segments {
+		if id <= plan.target {
+			seg.file.Close()
+			delete(d.segments, id)
+			stale = append(stale, id)
+		}
+	}
+	seg := &diskLogSegment{id: plan.target, file: tmp, size: size}
+	d.segments[plan.target] = seg
+	for i, rec := range plan.records {
+		if d.index[rec.key] != rec.entry {
+			seg.dead += rec.size
+			continue
+		}
+		rec.entry.segment, rec.entry.offset = plan.target, offsets[i]
+	}
+	// 删除失败的旧段在下次重建索引时被合并标记取代并删除
+	for _, id := range stale {
+		if id == plan.target {
+			continue
+		}
+		if err := os.Remove(d.segmentPath(id)); err != nil && !os.IsNotExist(err) {
+			return true, err
+		}
+	}
+	return true, nil
+}
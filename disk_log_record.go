@@ -0,0 +1,106 @@
+package go_cache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+// 段文件中记录的类型
+const (
+	diskLogOpPut    = 1 // 写入键值对
+	diskLogOpDelete = 2 // 删除键
+	// diskLogOpCompacted 合并生成的段或Clear之后新建的段的第一条记录，表示编号更小的段都已被取代
+	diskLogOpCompacted = 3
+)
+
+// 段文件由连续的记录组成，每条记录的布局如下，所有整数均为小端序：
+//
+//	[0:4]   之后所有字节的CRC32C
+//	[4]     记录类型
+//	[5:13]  过期时间的UnixNano，0表示永不过期
+//	[13:21] 滑动过期时长的纳秒数
+//	[21:25] 键的长度
+//	[25:29] 值的长度
+//	[29:33] 标签编码后的长度
+//
+// 之后依次是键、值和标签，标签的编码与二进制文件格式相同
+const diskLogHeaderSize = 33
+
+// encodeDiskLogRecord 编码一条记录，删除记录和合并标记只保存键
+func encodeDiskLogRecord(op byte, item *fileItem) []byte {
+	value := item.value()
+	tagsLen := tagsSize(item.Tags)
+	b := make([]byte, diskLogHeaderSize+len(item.Key)+len(value)+tagsLen)
+	b[4] = op
+	binary.LittleEndian.PutUint64(b[5:], uint64(unixNano(item.Expiration)))
+	binary.LittleEndian.PutUint64(b[13:], uint64(item.Sliding))
+	binary.LittleEndian.PutUint32(b[21:], uint32(len(item.Key)))
+	binary.LittleEndian.PutUint32(b[25:], uint32(len(value)))
+	binary.LittleEndian.PutUint32(b[29:], uint32(tagsLen))
+
+	pos := diskLogHeaderSize
+	pos += copy(b[pos:], item.Key)
+	pos += copy(b[pos:], value)
+	putTags(b[pos:], item.Tags)
+	binary.LittleEndian.PutUint32(b, crc32.Checksum(b[4:], crc32c))
+	return b
+}
+
+// diskLogRecordSize 根据记录头计算整条记录的字节数
+func diskLogRecordSize(header []byte) int64 {
+	return diskLogHeaderSize + int64(binary.LittleEndian.Uint32(header[21:])) +
+		int64(binary.LittleEndian.Uint32(header[25:])) + int64(binary.LittleEndian.Uint32(header[29:]))
+}
+
+// decodeDiskLogRecord 解析一条完整的记录，长度或校验和不匹配时返回ErrCorrupted
+func decodeDiskLogRecord(data []byte) (byte, *fileItem, error) {
+	if len(data) < diskLogHeaderSize || int64(len(data)) != diskLogRecordSize(data) {
+		return 0, nil, fmt.Errorf("%w: record length mismatch", ErrCorrupted)
+	}
+	if crc32.Checksum(data[4:], crc32c) != binary.LittleEndian.Uint32(data) {
+		return 0, nil, fmt.Errorf("%w: checksum mismatch", ErrCorrupted)
+	}
+	op := data[4]
+	if op != diskLogOpPut && op != diskLogOpDelete && op != diskLogOpCompacted {
+		return 0, nil, fmt.Errorf("%w: unknown record type %d", ErrCorrupted, op)
+	}
+
+	keyLen := int(binary.LittleEndian.Uint32(data[21:]))
+	valueLen := int(binary.LittleEndian.Uint32(data[25:]))
+	pos := diskLogHeaderSize
+	key := string(data[pos : pos+keyLen])
+	pos += keyLen
+	item := newFileItem(key, string(data[pos:pos+valueLen]), fromUnixNano(int64(binary.LittleEndian.Uint64(data[5:]))))
+	item.Sliding = time.Duration(binary.LittleEndian.Uint64(data[13:]))
+	pos += valueLen
+	tags, err := parseTags(data[pos:])
+	if err != nil {
+		return 0, nil, err
+	}
+	item.Tags = tags
+	return op, item, nil
+}
+
+// readDiskLogRecord 从r读取下一条记录，remaining为r中剩余的字节数，用于在读取前发现被截断或长度损坏的记录
+func readDiskLogRecord(r io.Reader, remaining int64) ([]byte, error) {
+	if remaining < diskLogHeaderSize {
+		return nil, fmt.Errorf("%w: truncated record", ErrCorrupted)
+	}
+	header := make([]byte, diskLogHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	size := diskLogRecordSize(header)
+	if size > remaining {
+		return nil, fmt.Errorf("%w: truncated record", ErrCorrupted)
+	}
+	data := make([]byte, size)
+	copy(data, header)
+	if _, err := io.ReadFull(r, data[diskLogHeaderSize:]); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
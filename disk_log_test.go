@@ -0,0 +1,430 @@
+package go_cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newDiskLogTestCache 在临时目录中创建日志缓存
+func newDiskLogTestCache(t *testing.T, clock Clock) *DiskLogCache {
+	cache, err := NewDiskLogCacheWithOptions(t.TempDir(), DiskLogCacheOptions{Clock: clock})
+	if err != nil {
+		t.Fatalf("创建日志缓存失败: %v", err)
+	}
+	return cache
+}
+
+// diskLogSegments 返回目录中的段文件名
+func diskLogSegments(t *testing.T, dir string) []string {
+	names, err := filepath.Glob(filepath.Join(dir, "*.seg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range names {
+		names[i] = filepath.Base(name)
+	}
+	return names
+}
+
+func TestDiskLogCache_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	clock := NewFakeClock(time.Now())
+	opts := DiskLogCacheOptions{Clock: clock}
+	cache, err := NewDiskLogCacheWithOptions(dir, opts)
+	if err != nil {
+		t.Fatalf("创建日志缓存失败: %v", err)
+	}
+
+	payload := string([]byte{0xff, 0x00, 0xfe})
+	_ = cache.Set("a", "1", 0)
+	_ = cache.Set("a", "2", time.Hour)
+	_ = cache.Set("deleted", "x", 0)
+	_ = cache.Delete("deleted")
+	_ = cache.Set("expired", "x", time.Second)
+	_ = cache.SetWithTags("tagged", payload, 0, "t")
+	_ = cache.SetSliding("sliding", "s", time.Minute)
+	_, _ = cache.Incr("n", 0)
+	_, _ = cache.Incr("n", 0)
+	if err := cache.Close(); err != nil {
+		t.Fatalf("关闭失败: %v", err)
+	}
+	if _, err := cache.Get("a"); !errors.Is(err, ErrClosed) {
+		t.Errorf("期望关闭后返回ErrClosed, 实际 %v", err)
+	}
+
+	clock.Advance(time.Minute)
+	cache, err = NewDiskLogCacheWithOptions(dir, opts)
+	if err != nil {
+		t.Fatalf("重新打开失败: %v", err)
+	}
+	defer cache.Close()
+	keys, _ := cache.Keys("*")
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != "[a n sliding tagged]" {
+		t.Errorf("期望重建索引后的键 [a n sliding tagged], 实际 %v", keys)
+	}
+	if got, _ := cache.Get("a"); got != "2" {
+		t.Errorf("期望读到最后写入的值, 实际 %q", got)
+	}
+	if ttl, _ := cache.TTL("a"); ttl != time.Hour-time.Minute {
+		t.Errorf("期望保留过期时间, 实际 %v", ttl)
+	}
+	if got, _ := cache.Get("tagged"); got != payload {
+		t.Errorf("期望值按原始字节保存, 实际 %q", got)
+	}
+	if keys, _ := cache.TagKeys("t"); fmt.Sprint(keys) != "[tagged]" {
+		t.Errorf("期望重建标签索引, 实际 %v", keys)
+	}
+	if got, _ := cache.Get("n"); got != "2" {
+		t.Errorf("期望计数器为2, 实际 %q", got)
+	}
+	// 滑动过期的时长同样保存在记录中
+	if ttl, _ := cache.TTL("sliding"); ttl != 0 {
+		t.Errorf("期望剩余0秒, 实际 %v", ttl)
+	}
+	_, _ = cache.Get("sliding")
+	if ttl, _ := cache.TTL("sliding"); ttl != time.Minute {
+		t.Errorf("期望读取时续期, 实际 %v", ttl)
+	}
+}
+
+func TestDiskLogCache_TornWrite(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskLogCache(dir)
+	if err != nil {
+		t.Fatalf("创建日志缓存失败: %v", err)
+	}
+	_ = cache.Set("a", "1", 0)
+	_ = cache.Set("b", "2", 0)
+	_ = cache.Close()
+
+	// 模拟写入最后一条记录时崩溃
+	segment := filepath.Join(dir, diskLogSegments(t, dir)[0])
+	info, _ := os.Stat(segment)
+	record := encodeDiskLogRecord(diskLogOpPut, newFileItem("c", "3", time.Time{}))
+	f, _ := os.OpenFile(segment, os.O_WRONLY|os.O_APPEND, 0644)
+	_, _ = f.Write(record[:len(record)-1])
+	f.Close()
+
+	cache, err = NewDiskLogCache(dir)
+	if err != nil {
+		t.Fatalf("期望截断不完整的记录后打开, 实际 %v", err)
+	}
+	if after, _ := os.Stat(segment); after.Size() != info.Size() {
+		t.Errorf("期望段文件截断为 %d 字节, 实际 %d", info.Size(), after.Size())
+	}
+	if exists, _ := cache.Exists("c"); exists {
+		t.Error("期望不完整的记录被丢弃")
+	}
+	_ = cache.Set("c", "4", 0)
+	_ = cache.Close()
+
+	cache, _ = NewDiskLogCache(dir)
+	defer cache.Close()
+	values, _ := cache.GetMulti([]string{"a", "b", "c"})
+	if fmt.Sprint(values) != "map[a:1 b:2 c:4]" {
+		t.Errorf("期望 map[a:1 b:2 c:4], 实际 %v", values)
+	}
+}
+
+func TestDiskLogCache_CorruptedSegment(t *testing.T) {
+	dir := t.TempDir()
+	cache, _ := NewDiskLogCacheWithOptions(dir, DiskLogCacheOptions{SegmentSize: 1})
+	_ = cache.Set("a", "1", 0)
+	_ = cache.Set("b", "2", 0)
+	_ = cache.Close()
+
+	// 不是最后一个段的损坏不能截断，否则之后的删除记录可能丢失
+	segments := diskLogSegments(t, dir)
+	first := filepath.Join(dir, segments[0])
+	data, _ := os.ReadFile(first)
+	data[diskLogHeaderSize] ^= 0x01
+	_ = os.WriteFile(first, data, 0644)
+	if _, err := NewDiskLogCache(dir); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("期望返回ErrCorrupted, 实际 %v", err)
+	}
+}
+
+func TestDiskLogCache_Compact(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskLogCacheWithOptions(dir, DiskLogCacheOptions{SegmentSize: 256})
+	if err != nil {
+		t.Fatalf("创建日志缓存失败: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		_ = cache.Set(fmt.Sprintf("k%d", i%5), i, 0)
+	}
+	_ = cache.SetWithTags("gone", "v", 0, "t")
+	_ = cache.InvalidateTag("t")
+	if n := len(diskLogSegments(t, dir)); n < 5 {
+		t.Fatalf("期望写入多个段, 实际 %d", n)
+	}
+
+	// 保留合并前的段，用于模拟删除旧段之前崩溃
+	old := map[string][]byte{}
+	for _, name := range diskLogSegments(t, dir) {
+		old[name], _ = os.ReadFile(filepath.Join(dir, name))
+	}
+	if err := cache.Compact(); err != nil {
+		t.Fatalf("合并失败: %v", err)
+	}
+	segments := diskLogSegments(t, dir)
+	if len(segments) != 2 {
+		t.Errorf("期望合并为一个段加上新的当前段, 实际 %v", segments)
+	}
+	merged, _ := os.Stat(filepath.Join(dir, segments[0]))
+	if want := int64(len(encodeDiskLogRecord(diskLogOpCompacted, &fileItem{}))) + 5*int64(len(encodeDiskLogRecord(diskLogOpPut, newFileItem("k0", "45", time.Time{})))); merged.Size() != want {
+		t.Errorf("期望合并后只保留5个键的记录共 %d 字节, 实际 %d", want, merged.Size())
+	}
+	for i := 0; i < 5; i++ {
+		if got, _ := cache.Get(fmt.Sprintf("k%d", i)); got != fmt.Sprint(45+i) {
+			t.Errorf("期望k%d为 %d, 实际 %q", i, 45+i, got)
+		}
+	}
+	_ = cache.Set("k0", "new", 0)
+	_ = cache.Close()
+
+	for name, data := range old {
+		if name < segments[0] {
+			_ = os.WriteFile(filepath.Join(dir, name), data, 0644)
+		}
+	}
+	cache, err = NewDiskLogCache(dir)
+	if err != nil {
+		t.Fatalf("重新打开失败: %v", err)
+	}
+	defer cache.Close()
+	keys, _ := cache.Keys("*")
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != "[k0 k1 k2 k3 k4]" {
+		t.Errorf("期望忽略被合并取代的旧段, 实际 %v", keys)
+	}
+	if got, _ := cache.Get("k0"); got != "new" {
+		t.Errorf("期望合并后的写入仍然有效, 实际 %q", got)
+	}
+	if got := diskLogSegments(t, dir); fmt.Sprint(got) != fmt.Sprint(segments) {
+		t.Errorf("期望删除被取代的旧段, 实际 %v", got)
+	}
+}
+
+func TestDiskLogCache_BackgroundCompact(t *testing.T) {
+	dir := t.TempDir()
+	clock := NewFakeClock(time.Now())
+	cache, err := NewDiskLogCacheWithOptions(dir, DiskLogCacheOptions{Clock: clock, CompactInterval: time.Minute})
+	if err != nil {
+		t.Fatalf("创建日志缓存失败: %v", err)
+	}
+	defer cache.Close()
+	recorder := &evictRecorder{}
+	cache.OnEvict(recorder.record)
+
+	_ = cache.Set("a", "1", 0)
+	_ = cache.Set("b", "2", 0)
+	clock.Advance(time.Minute)
+	if n := len(diskLogSegments(t, dir)); n != 1 {
+		t.Errorf("期望没有无效记录时不合并, 实际 %d 个段", n)
+	}
+
+	_ = cache.Set("a", "3", 0)
+	_ = cache.Set("b", "4", 30*time.Second)
+	_ = cache.Set("a", "5", 0)
+	recorder.take()
+	clock.Advance(time.Minute)
+	if got := recorder.take(); fmt.Sprint(got) != "[expired:b=4]" {
+		t.Errorf("期望后台删除过期的键, 实际事件 %v", got)
+	}
+	segments := diskLogSegments(t, dir)
+	if len(segments) != 2 {
+		t.Errorf("期望无效记录超过一半时合并, 实际 %v", segments)
+	}
+	if got, _ := cache.Get("a"); got != "5" {
+		t.Errorf("期望合并后值不变, 实际 %q", got)
+	}
+}
+
+func TestDiskLogCache_Lock(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "aix" || runtime.GOOS == "solaris" || runtime.GOOS == "illumos" {
+		t.Skip("当前平台不支持flock")
+	}
+	dir := t.TempDir()
+	cache, err := NewDiskLogCache(dir)
+	if err != nil {
+		t.Fatalf("创建日志缓存失败: %v", err)
+	}
+	if _, err := NewDiskLogCache(dir); !errors.Is(err, ErrLocked) {
+		t.Errorf("期望同一目录不能被打开两次, 实际 %v", err)
+	}
+	_ = cache.Close()
+	cache, err = NewDiskLogCache(dir)
+	if err != nil {
+		t.Fatalf("期望关闭后可以重新打开, 实际 %v", err)
+	}
+	_ = cache.Close()
+}
+
+func TestDiskLogCache_Factory(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewCache(CacheConfig{Type: DiskLogCacheType, DiskLogDir: dir, DiskLogSegmentSize: 1 << 10})
+	if err != nil {
+		t.Fatalf("创建日志缓存失败: %v", err)
+	}
+	defer cache.Close()
+	if d, ok := cache.(*DiskLogCache); !ok || d.segmentSize != 1<<10 {
+		t.Errorf("期望创建段大小为1KB的日志缓存, 实际 %T", cache)
+	}
+	if _, err := NewDiskLogCacheWithOptions(t.TempDir(), DiskLogCacheOptions{CompactRatio: 2}); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("期望无效的合并比例返回ErrInvalidParameter, 实际 %v", err)
+	}
+}
+
+func TestDiskLogCache_Concurrent(t *testing.T) {
+	cache, err := NewDiskLogCacheWithOptions(t.TempDir(), DiskLogCacheOptions{SegmentSize: 1 << 10})
+	if err != nil {
+		t.Fatalf("创建日志缓存失败: %v", err)
+	}
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				key := fmt.Sprintf("k%d", i%10)
+				_ = cache.Set(key, i, 0)
+				if _, err := cache.Get(key); err != nil && err != ErrKeyNotFound {
+					t.Errorf("读取失败: %v", err)
+				}
+				if i%50 == 0 {
+					_ = cache.Compact()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if keys, _ := cache.Keys("*"); len(keys) != 10 {
+		t.Errorf("期望10个键, 实际 %v", keys)
+	}
+}
+
+func TestDiskLogCache_CompactConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskLogCache(dir)
+	if err != nil {
+		t.Fatalf("创建日志缓存失败: %v", err)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		_ = cache.Set(key, "old", 0)
+	}
+
+	// 记录快照后、安装新段前修改键，模拟复制期间的并发写入
+	var plan *diskLogCompaction
+	_ = cache.update(func(*evictEvents) error {
+		plan, err = cache.planCompactLocked()
+		return err
+	})
+	if plan == nil || len(plan.records) != 3 {
+		t.Fatalf("期望快照包含3条记录, 实际 %+v %v", plan, err)
+	}
+	_ = cache.Set("a", "new", 0)
+	_ = cache.Delete("b")
+	tmp, offsets, size, err := cache.writeCompacted(plan)
+	if err != nil {
+		t.Fatalf("复制记录失败: %v", err)
+	}
+	// 复制期间读取不需要等待
+	if got, _ := cache.Get("c"); got != "old" {
+		t.Errorf("期望复制期间可以读取c, 实际 %q", got)
+	}
+	if err := cache.update(func(*evictEvents) error {
+		_, err := cache.installCompactedLocked(plan, tmp, offsets, size)
+		return err
+	}); err != nil {
+		t.Fatalf("安装新段失败: %v", err)
+	}
+	if dead := cache.segments[plan.target].dead; dead != 2*plan.records[0].size {
+		t.Errorf("期望被修改的两个键的记录计为无效, 实际 %d", dead)
+	}
+
+	check := func(cache *DiskLogCache) {
+		t.Helper()
+		want := map[string]string{"a": "new", "c": "old"}
+		for key, value := range want {
+			if got, _ := cache.Get(key); got != value {
+				t.Errorf("期望%s为%s, 实际 %q", key, value, got)
+			}
+		}
+		if _, err := cache.Get("b"); err != ErrKeyNotFound {
+			t.Errorf("期望b已被删除, 实际 %v", err)
+		}
+	}
+	check(cache)
+	_ = cache.Close()
+	cache, err = NewDiskLogCache(dir)
+	if err != nil {
+		t.Fatalf("重新打开失败: %v", err)
+	}
+	defer cache.Close()
+	check(cache)
+}
+
+func TestDiskLogCache_ReadLock(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cache := newDiskLogTestCache(t, clock)
+	defer cache.Close()
+	recorder := &evictRecorder{}
+	cache.OnEvict(recorder.record)
+
+	_ = cache.SetWithTags("k", "v", 0, "t")
+	_ = cache.SetSliding("s", "v", time.Minute)
+	_ = cache.Set("old", "v", time.Second)
+	clock.Advance(2 * time.Second)
+
+	// 持有读锁时只读的操作仍然可以完成，不需要等待写锁
+	done := make(chan struct{})
+	cache.mu.RLock()
+	go func() {
+		defer close(done)
+		if ok, _ := cache.Exists("k"); !ok {
+			t.Error("期望k存在")
+		}
+		if ttl, _ := cache.TTL("k"); ttl != -1 {
+			t.Errorf("期望k永不过期, 实际 %v", ttl)
+		}
+		if keys, _ := cache.Keys("[ks]"); len(keys) != 2 {
+			t.Errorf("期望Keys返回k和s, 实际 %v", keys)
+		}
+		if keys, _ := cache.TagKeys("t"); len(keys) != 1 {
+			t.Errorf("期望TagKeys返回k, 实际 %v", keys)
+		}
+		// 续期时间不足ttl/10时不追加记录
+		if got, _ := cache.Get("s"); got != "v" {
+			t.Errorf("期望读取s, 实际 %q", got)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("期望只读的操作不等待写锁")
+	}
+	cache.mu.RUnlock()
+	<-done
+
+	// 遇到的过期键在释放读锁后删除并通知
+	if ok, _ := cache.Exists("old"); ok {
+		t.Error("期望过期的键不存在")
+	}
+	if got := recorder.take(); fmt.Sprint(got) != "[expired:old=v]" {
+		t.Errorf("期望通知过期事件, 实际 %v", got)
+	}
+	if _, ok := cache.index["old"]; ok {
+		t.Error("期望过期的键从索引中删除")
+	}
+}
@@ -1,6 +1,11 @@
 package go_cache
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
 
 var (
 	// ErrKeyNotFound 表示键未找到的错误
@@ -11,4 +16,49 @@ var (
 
 	// ErrInvalidParameter 表示参数无效错误
 	ErrInvalidParameter = errors.New("invalid parameter")
+
+	// ErrSerialization 表示值序列化失败
+	ErrSerialization = errors.New("serialization error")
+
+	// ErrCorrupted 表示缓存数据损坏，无法解析
+	ErrCorrupted = errors.New("corrupted cache data")
+
+	// ErrUnsupportedFormat 表示缓存文件的格式版本不受支持，通常由更新版本的程序写入
+	ErrUnsupportedFormat = errors.New("unsupported cache file format")
+
+	// ErrLocked 表示缓存目录已被其他实例打开
+	ErrLocked = errors.New("cache directory is locked by another instance")
+
+	// ErrClosed 表示缓存已关闭
+	ErrClosed = errors.New("cache is closed")
+
+	// ErrNotInteger 表示计数器的值不是整数或计算结果溢出
+	ErrNotInteger = errors.New("value is not an integer or out of range")
 )
+
+// BatchError 表示批量操作中部分键失败，Errors记录每个失败键对应的错误
+type BatchError struct {
+	Errors map[string]error
+}
+
+func (e *BatchError) Error() string {
+	keys := make([]string, 0, len(e.Errors))
+	for key := range e.Errors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s: %v", key, e.Errors[key]))
+	}
+	return fmt.Sprintf("batch operation failed for %d keys: %s", len(keys), strings.Join(parts, "; "))
+}
+
+// newBatchError 根据每个键的错误创建BatchError，没有错误时返回nil
+func newBatchError(errs map[string]error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &BatchError{Errors: errs}
+}
@@ -0,0 +1,116 @@
+package go_cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// EvictReason 条目离开缓存的原因
+type EvictReason int
+
+const (
+	// EvictReasonExpired 条目已过期
+	EvictReasonExpired EvictReason = iota + 1
+
+	// EvictReasonEvicted 条目因容量限制被淘汰
+	EvictReasonEvicted
+
+	// EvictReasonDeleted 条目被显式删除，包括Delete、标签失效和Clear
+	EvictReasonDeleted
+
+	// EvictReasonReplaced 条目被新值覆盖，回调收到的是旧值
+	EvictReasonReplaced
+)
+
+// String 返回原因的名称
+func (r EvictReason) String() string {
+	switch r {
+	case EvictReasonExpired:
+		return "expired"
+	case EvictReasonEvicted:
+		return "evicted"
+	case EvictReasonDeleted:
+		return "deleted"
+	case EvictReasonReplaced:
+		return "replaced"
+	default:
+		return "unknown"
+	}
+}
+
+// evictEvent 表示一个等待回调的移除事件
+type evictEvent struct {
+	key    string
+	value  string
+	reason EvictReason
+}
+
+// evictNotifier 保存OnEvict注册的监听函数
+type evictNotifier struct {
+	mu        sync.RWMutex
+	listeners []func(key, value string, reason EvictReason)
+	active    atomic.Bool // 是否有监听函数，没有时不收集事件
+}
+
+// add 注册监听函数
+func (n *evictNotifier) add(fn func(key, value string, reason EvictReason)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.listeners = append(n.listeners, fn)
+	n.active.Store(true)
+}
+
+// enabled 判断是否需要收集事件
+func (n *evictNotifier) enabled() bool {
+	return n != nil && n.active.Load()
+}
+
+// notify 依次调用监听函数，调用方不能持有缓存锁
+func (n *evictNotifier) notify(events []evictEvent) {
+	if len(events) == 0 {
+		return
+	}
+	n.mu.RLock()
+	listeners := n.listeners
+	n.mu.RUnlock()
+	for _, e := range events {
+		for _, fn := range listeners {
+			fn(e.key, e.value, e.reason)
+		}
+	}
+}
+
+// evictEvents 收集一次操作中产生的移除事件，释放锁后通过flush回调
+type evictEvents struct {
+	notifier *evictNotifier
+	events   []evictEvent
+}
+
+// collect 开始收集事件，没有监听函数时返回nil，nil的evictEvents忽略所有事件
+func (n *evictNotifier) collect() *evictEvents {
+	if !n.enabled() {
+		return nil
+	}
+	return &evictEvents{notifier: n}
+}
+
+// enabled 判断是否需要记录事件
+func (e *evictEvents) enabled() bool {
+	return e != nil
+}
+
+// add 记录一个事件
+func (e *evictEvents) add(key, value string, reason EvictReason) {
+	if e != nil {
+		e.events = append(e.events, evictEvent{key: key, value: value, reason: reason})
+	}
+}
+
+// flush 回调已记录的事件，调用方不能持有缓存锁
+func (e *evictEvents) flush() {
+	if e != nil {
+		events := e.events
+		e.events = nil
+		e.notifier.notify(events)
+	}
+}
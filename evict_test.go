@@ -0,0 +1,142 @@
+package go_cache
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// evictRecorder 记录收到的移除事件
+type evictRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *evictRecorder) record(key, value string, reason EvictReason) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, fmt.Sprintf("%s:%s=%s", reason, key, value))
+}
+
+// take 返回并清空已记录的事件
+func (r *evictRecorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.events
+	r.events = nil
+	return events
+}
+
+func TestEvictObserver(t *testing.T) {
+	defer Init()()
+	fileCache, err := NewFileCache(testFilePath)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	caches := map[string]Cache{
+		"memory":  NewMemoryCache(),
+		"sharded": NewMemoryCacheWithOptions(MemoryCacheOptions{Shards: 4}),
+		"arena":   newArenaTestCache(),
+		"file":    fileCache,
+		"disklog": newDiskLogTestCache(t, nil),
+		"binary":  newBinaryFileTestCache(t, nil),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			defer cache.Close()
+			recorder := &evictRecorder{}
+			cache.(EvictObserver).OnEvict(recorder.record)
+			// 回调在锁外执行，可以再次访问缓存
+			cache.(EvictObserver).OnEvict(func(key, value string, reason EvictReason) {
+				_, _ = cache.Exists(key)
+			})
+
+			_ = cache.Set("a", "1", 0)
+			_ = cache.Set("a", "2", 0)
+			_ = cache.Delete("a")
+			_ = cache.Delete("a")
+			_, _ = cache.(CounterCache).Incr("n", 0)
+			_, _ = cache.(CounterCache).Incr("n", 0)
+			_, _ = cache.(ConditionalCache).SetNX("c", "x", 0)
+			_, _ = cache.(ConditionalCache).CompareAndSwap("c", "x", "y", 0)
+			_ = cache.(TagCache).SetWithTags("t", "v", 0, "tag")
+			_ = cache.(TagCache).InvalidateTag("tag")
+			want := []string{"replaced:a=1", "deleted:a=2", "replaced:n=1", "replaced:c=x", "deleted:t=v"}
+			if got := recorder.take(); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("期望事件 %v, 实际 %v", want, got)
+			}
+
+			// 已过期的条目被覆盖时原因为expired
+			_ = cache.Set("e", "old", 5*time.Millisecond)
+			time.Sleep(10 * time.Millisecond)
+			_ = cache.Set("e", "new", 0)
+			if got := recorder.take(); fmt.Sprint(got) != "[expired:e=old]" {
+				t.Errorf("期望事件 [expired:e=old], 实际 %v", got)
+			}
+
+			_ = cache.(ClearableCache).Clear()
+			got := recorder.take()
+			sort.Strings(got)
+			if fmt.Sprint(got) != "[deleted:c=y deleted:e=new deleted:n=2]" {
+				t.Errorf("期望Clear通知所有条目, 实际 %v", got)
+			}
+		})
+	}
+}
+
+func TestMemoryCache_EvictReasons(t *testing.T) {
+	var evicted []string
+	clock := NewFakeClock(time.Now())
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{
+		MaxEntries:      2,
+		JanitorInterval: time.Second,
+		Clock:           clock,
+		OnEvicted: func(key, value string) {
+			evicted = append(evicted, key)
+		},
+	})
+	defer cache.Close()
+	recorder := &evictRecorder{}
+	cache.OnEvict(recorder.record)
+
+	_ = cache.Set("a", "1", 0)
+	_ = cache.Set("b", "2", 0)
+	_ = cache.Set("c", "3", 0)
+	_ = cache.Set("c", "4", 0)
+	if got := recorder.take(); fmt.Sprint(got) != "[evicted:a=1 replaced:c=3]" {
+		t.Errorf("期望事件 [evicted:a=1 replaced:c=3], 实际 %v", got)
+	}
+	if fmt.Sprint(evicted) != "[a]" {
+		t.Errorf("期望OnEvicted只收到淘汰事件, 实际 %v", evicted)
+	}
+
+	// 后台清理删除过期条目
+	_ = cache.Expire("b", time.Millisecond)
+	clock.Advance(time.Second)
+	if got := recorder.take(); fmt.Sprint(got) != "[expired:b=2]" {
+		t.Errorf("期望后台清理通知 [expired:b=2], 实际 %v", got)
+	}
+}
+
+func TestFileCache_EvictOnRead(t *testing.T) {
+	defer Init()()
+	clock := NewFakeClock(time.Now())
+	cache, err := NewFileCacheWithOptions(testFilePath, FileCacheOptions{Clock: clock})
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	recorder := &evictRecorder{}
+	cache.OnEvict(recorder.record)
+
+	_ = cache.Set("k", "v", time.Minute)
+	clock.Advance(time.Minute + time.Second)
+	if _, err := cache.Get("k"); err != ErrKeyNotFound {
+		t.Errorf("期望过期的键不存在, 实际 %v", err)
+	}
+	if got := recorder.take(); fmt.Sprint(got) != "[expired:k=v]" {
+		t.Errorf("期望读取时通知过期事件, 实际 %v", got)
+	}
+}
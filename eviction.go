@@ -0,0 +1,215 @@
+package go_cache
+
+import (
+	"container/list"
+)
+
+// EvictionPolicy 决定容量受限的MemoryCache淘汰哪个条目。
+// 缓存在持锁时调用这些方法，实现不需要保证并发安全
+type EvictionPolicy interface {
+	// Add 记录一次写入，覆盖写入已存在的键时同样会调用
+	Add(key string)
+
+	// Access 记录一次读取命中
+	Access(key string)
+
+	// Remove 键被删除、过期或清空时调用，键不存在时忽略
+	Remove(key string)
+
+	// Victim 选出并移除下一个应被淘汰的键，可以是刚写入的键（表示拒绝准入），没有可淘汰的键时返回false
+	Victim() (string, bool)
+}
+
+// EvictionPolicyType 内置淘汰策略的类型
+type EvictionPolicyType string
+
+const (
+	// LRUEviction 淘汰最久未访问的条目
+	LRUEviction EvictionPolicyType = "lru"
+
+	// LFUEviction 淘汰访问次数最少的条目，次数相同时淘汰最久未访问的
+	LFUEviction EvictionPolicyType = "lfu"
+
+	// ARCEviction 自适应替换缓存，在最近访问和访问频率之间自动平衡，抗扫描
+	ARCEviction EvictionPolicyType = "arc"
+
+	// TinyLFUEviction W-TinyLFU，使用count-min sketch做准入控制，窗口LRU吸收突发流量
+	TinyLFUEviction EvictionPolicyType = "tinylfu"
+)
+
+// defaultPolicyCapacity 只限制字节数时，ARC和W-TinyLFU估算容量使用的条目数
+const defaultPolicyCapacity = 10000
+
+// NewEvictionPolicy 创建内置的淘汰策略，capacity为缓存最多保存的条目数，
+// 用于确定ARC的历史记录长度和W-TinyLFU的各区大小；类型为空时使用LRU
+func NewEvictionPolicy(policyType EvictionPolicyType, capacity int) (EvictionPolicy, error) {
+	if capacity <= 0 {
+		capacity = defaultPolicyCapacity
+	}
+	switch policyType {
+	case LRUEviction, "":
+		return newLRUPolicy(), nil
+	case LFUEviction:
+		return newLFUPolicy(), nil
+	case ARCEviction:
+		return newARCPolicy(capacity), nil
+	case TinyLFUEviction:
+		return newTinyLFUPolicy(capacity), nil
+	default:
+		return nil, ErrInvalidParameter
+	}
+}
+
+// lruList 按最近访问顺序维护键，所有操作均为O(1)
+type lruList struct {
+	ll    *list.List // 表头为最近访问的键
+	elems map[string]*list.Element
+}
+
+func newLRUList() *lruList {
+	return &lruList{
+		ll:    list.New(),
+		elems: make(map[string]*list.Element),
+	}
+}
+
+// add 将键放到表头
+func (l *lruList) add(key string) {
+	if e, ok := l.elems[key]; ok {
+		l.ll.MoveToFront(e)
+		return
+	}
+	l.elems[key] = l.ll.PushFront(key)
+}
+
+// touch 将已存在的键移动到表头
+func (l *lruList) touch(key string) {
+	if e, ok := l.elems[key]; ok {
+		l.ll.MoveToFront(e)
+	}
+}
+
+// remove 移除键，返回键是否存在
+func (l *lruList) remove(key string) bool {
+	e, ok := l.elems[key]
+	if ok {
+		l.ll.Remove(e)
+		delete(l.elems, key)
+	}
+	return ok
+}
+
+// has 判断键是否在表中
+func (l *lruList) has(key string) bool {
+	_, ok := l.elems[key]
+	return ok
+}
+
+// back 返回最久未被访问的键
+func (l *lruList) back() (string, bool) {
+	e := l.ll.Back()
+	if e == nil {
+		return "", false
+	}
+	return e.Value.(string), true
+}
+
+// pop 移除并返回最久未被访问的键
+func (l *lruList) pop() (string, bool) {
+	key, ok := l.back()
+	if ok {
+		l.remove(key)
+	}
+	return key, ok
+}
+
+func (l *lruList) len() int {
+	return l.ll.Len()
+}
+
+// lruPolicy 淘汰最久未访问的条目
+type lruPolicy struct {
+	keys *lruList
+}
+
+func newLRUPolicy() *lruPolicy {
+	return &lruPolicy{keys: newLRUList()}
+}
+
+func (p *lruPolicy) Add(key string)         { p.keys.add(key) }
+func (p *lruPolicy) Access(key string)      { p.keys.touch(key) }
+func (p *lruPolicy) Remove(key string)      { p.keys.remove(key) }
+func (p *lruPolicy) Victim() (string, bool) { return p.keys.pop() }
+
+// lfuBucket 保存访问次数相同的键，桶内按最近访问排序
+type lfuBucket struct {
+	freq int
+	keys *lruList
+}
+
+// lfuPolicy 淘汰访问次数最少的条目，桶按访问次数递增排列，所有操作均为O(1)
+type lfuPolicy struct {
+	buckets *list.List // 元素为*lfuBucket
+	entries map[string]*list.Element
+}
+
+func newLFUPolicy() *lfuPolicy {
+	return &lfuPolicy{
+		buckets: list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (p *lfuPolicy) Add(key string) {
+	if _, ok := p.entries[key]; ok {
+		p.Access(key)
+		return
+	}
+	front := p.buckets.Front()
+	if front == nil || front.Value.(*lfuBucket).freq != 1 {
+		front = p.buckets.PushFront(&lfuBucket{freq: 1, keys: newLRUList()})
+	}
+	front.Value.(*lfuBucket).keys.add(key)
+	p.entries[key] = front
+}
+
+func (p *lfuPolicy) Access(key string) {
+	e, ok := p.entries[key]
+	if !ok {
+		return
+	}
+	bucket := e.Value.(*lfuBucket)
+	next := e.Next()
+	if next == nil || next.Value.(*lfuBucket).freq != bucket.freq+1 {
+		next = p.buckets.InsertAfter(&lfuBucket{freq: bucket.freq + 1, keys: newLRUList()}, e)
+	}
+	next.Value.(*lfuBucket).keys.add(key)
+	p.entries[key] = next
+	p.removeFromBucket(e, key)
+}
+
+func (p *lfuPolicy) Remove(key string) {
+	if e, ok := p.entries[key]; ok {
+		delete(p.entries, key)
+		p.removeFromBucket(e, key)
+	}
+}
+
+func (p *lfuPolicy) Victim() (string, bool) {
+	front := p.buckets.Front()
+	if front == nil {
+		return "", false
+	}
+	key, _ := front.Value.(*lfuBucket).keys.back()
+	p.Remove(key)
+	return key, true
+}
+
+// removeFromBucket 从桶中移除键，桶为空时删除桶
+func (p *lfuPolicy) removeFromBucket(e *list.Element, key string) {
+	bucket := e.Value.(*lfuBucket)
+	bucket.keys.remove(key)
+	if bucket.keys.len() == 0 {
+		p.buckets.Remove(e)
+	}
+}
@@ -0,0 +1,86 @@
+package go_cache
+
+// arcPolicy 自适应替换缓存（Megiddo & Modha, 2003）。
+// t1保存只访问过一次的键，t2保存多次访问的键，b1和b2分别记录最近从t1和t2淘汰的键，
+// 命中历史记录时调整t1的目标大小p，从而在最近访问和访问频率之间自动平衡
+type arcPolicy struct {
+	capacity int
+	p        int // t1的目标大小
+	t1, t2   *lruList
+	b1, b2   *lruList
+	fromB2   bool // 最近一次写入是否命中b2，用于Victim的选择
+}
+
+func newARCPolicy(capacity int) *arcPolicy {
+	return &arcPolicy{
+		capacity: capacity,
+		t1:       newLRUList(),
+		t2:       newLRUList(),
+		b1:       newLRUList(),
+		b2:       newLRUList(),
+	}
+}
+
+func (a *arcPolicy) Add(key string) {
+	a.fromB2 = false
+	switch {
+	case a.t1.has(key) || a.t2.has(key):
+		a.Access(key)
+		return
+	case a.b1.has(key):
+		a.p = min(a.capacity, a.p+max(a.b2.len()/a.b1.len(), 1))
+		a.b1.remove(key)
+		a.t2.add(key)
+		return
+	case a.b2.has(key):
+		a.p = max(0, a.p-max(a.b1.len()/a.b2.len(), 1))
+		a.b2.remove(key)
+		a.t2.add(key)
+		a.fromB2 = true
+		return
+	}
+	// 全新的键，限制历史记录的长度
+	if a.t1.len()+a.b1.len() >= a.capacity && a.b1.len() > 0 {
+		a.b1.pop()
+	} else if a.t1.len()+a.t2.len()+a.b1.len()+a.b2.len() >= 2*a.capacity && a.b2.len() > 0 {
+		a.b2.pop()
+	}
+	a.t1.add(key)
+}
+
+func (a *arcPolicy) Access(key string) {
+	if a.t1.remove(key) {
+		a.t2.add(key)
+		return
+	}
+	a.t2.touch(key)
+}
+
+func (a *arcPolicy) Remove(key string) {
+	// 主动删除的键不进入历史记录
+	if !a.t1.remove(key) {
+		a.t2.remove(key)
+	}
+}
+
+func (a *arcPolicy) Victim() (string, bool) {
+	t1Len := a.t1.len()
+	if t1Len > 0 && (t1Len > a.p || (a.fromB2 && t1Len == a.p) || a.t2.len() == 0) {
+		key, _ := a.t1.pop()
+		a.ghost(a.b1, key)
+		return key, true
+	}
+	if key, ok := a.t2.pop(); ok {
+		a.ghost(a.b2, key)
+		return key, true
+	}
+	return "", false
+}
+
+// ghost 将淘汰的键记入历史记录，历史记录不超过容量
+func (a *arcPolicy) ghost(b *lruList, key string) {
+	b.add(key)
+	for b.len() > a.capacity {
+		b.pop()
+	}
+}
@@ -0,0 +1,254 @@
+package go_cache
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var evictionPolicyTypes = []EvictionPolicyType{LRUEviction, LFUEviction, ARCEviction, TinyLFUEviction}
+
+func TestEvictionPolicy_Bounded(t *testing.T) {
+	for _, policyType := range evictionPolicyTypes {
+		t.Run(string(policyType), func(t *testing.T) {
+			cache := NewMemoryCacheWithOptions(MemoryCacheOptions{MaxEntries: 50, EvictionPolicy: policyType})
+			defer cache.Close()
+
+			rng := rand.New(rand.NewSource(1))
+			for i := 0; i < 5000; i++ {
+				key := fmt.Sprintf("key%d", rng.Intn(200))
+				switch rng.Intn(4) {
+				case 0:
+					_ = cache.Delete(key)
+				case 1:
+					_, _ = cache.Get(key)
+				default:
+					_ = cache.Set(key, "v", 0)
+				}
+				if cache.shards[0].store.len() > 50 {
+					t.Fatalf("第%d次操作后条目数 %d 超出限制", i, cache.shards[0].store.len())
+				}
+			}
+
+			// 策略中的键与缓存中的键保持一致：不断淘汰直到策略为空
+			seen := make(map[string]bool)
+			for {
+				key, ok := cache.shards[0].policy.Victim()
+				if !ok {
+					break
+				}
+				if seen[key] {
+					t.Fatalf("键 %s 被重复淘汰", key)
+				}
+				seen[key] = true
+				if _, exists := cache.shards[0].store.get(key); !exists {
+					t.Errorf("淘汰的键 %s 不在缓存中", key)
+				}
+			}
+			if len(seen) != cache.shards[0].store.len() {
+				t.Errorf("期望策略跟踪 %d 个键, 实际 %d", cache.shards[0].store.len(), len(seen))
+			}
+		})
+	}
+}
+
+func TestEvictionPolicy_LFU(t *testing.T) {
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{MaxEntries: 3, EvictionPolicy: LFUEviction})
+	defer cache.Close()
+
+	_ = cache.Set("a", "1", 0)
+	_ = cache.Set("b", "2", 0)
+	_ = cache.Set("c", "3", 0)
+	_, _ = cache.Get("a")
+	_, _ = cache.Get("a")
+	_, _ = cache.Get("b")
+	_ = cache.Set("d", "4", 0)
+
+	// c访问次数最少
+	if exists, _ := cache.Exists("c"); exists {
+		t.Error("期望c被淘汰")
+	}
+	// d与c一样只写入过一次，但b的访问次数更多
+	_ = cache.Set("e", "5", 0)
+	if exists, _ := cache.Exists("d"); exists {
+		t.Error("期望d被淘汰")
+	}
+	for _, key := range []string{"a", "b", "e"} {
+		if exists, _ := cache.Exists(key); !exists {
+			t.Errorf("期望 %s 仍然存在", key)
+		}
+	}
+}
+
+func TestEvictionPolicy_ScanResistant(t *testing.T) {
+	for _, policyType := range []EvictionPolicyType{ARCEviction, TinyLFUEviction} {
+		t.Run(string(policyType), func(t *testing.T) {
+			cache := NewMemoryCacheWithOptions(MemoryCacheOptions{MaxEntries: 100, EvictionPolicy: policyType})
+			defer cache.Close()
+
+			// 热点键被反复访问
+			for round := 0; round < 5; round++ {
+				for i := 0; i < 50; i++ {
+					key := fmt.Sprintf("hot%d", i)
+					if _, err := cache.Get(key); err != nil {
+						_ = cache.Set(key, "v", 0)
+					}
+				}
+			}
+			// 一次性扫描大量冷键
+			for i := 0; i < 1000; i++ {
+				_ = cache.Set(fmt.Sprintf("scan%d", i), "v", 0)
+			}
+
+			hits := 0
+			for i := 0; i < 50; i++ {
+				if exists, _ := cache.Exists(fmt.Sprintf("hot%d", i)); exists {
+					hits++
+				}
+			}
+			if hits < 40 {
+				t.Errorf("期望扫描后大部分热点键仍然存在, 实际只剩 %d 个", hits)
+			}
+		})
+	}
+}
+
+func TestEvictionPolicy_Custom(t *testing.T) {
+	var capacity int
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{
+		MaxEntries: 2,
+		NewEvictionPolicy: func(c int) EvictionPolicy {
+			capacity = c
+			return newLFUPolicy()
+		},
+	})
+	defer cache.Close()
+
+	if capacity != 2 {
+		t.Errorf("期望自定义策略收到容量2, 实际 %d", capacity)
+	}
+	if _, ok := cache.shards[0].policy.(*lfuPolicy); !ok {
+		t.Errorf("期望使用自定义策略, 实际 %T", cache.shards[0].policy)
+	}
+	_ = cache.Clear()
+	if _, ok := cache.shards[0].policy.(*lfuPolicy); !ok {
+		t.Errorf("期望Clear后仍使用自定义策略, 实际 %T", cache.shards[0].policy)
+	}
+}
+
+func TestNewCache_EvictionPolicy(t *testing.T) {
+	cache, err := NewCache(CacheConfig{Type: MemoryCacheType, MemoryMaxEntries: 10, MemoryEvictionPolicy: ARCEviction})
+	if err != nil {
+		t.Fatalf("创建缓存失败: %v", err)
+	}
+	defer cache.Close()
+	if _, ok := cache.(*MemoryCache).shards[0].policy.(*arcPolicy); !ok {
+		t.Errorf("期望使用ARC策略, 实际 %T", cache.(*MemoryCache).shards[0].policy)
+	}
+
+	if _, err := NewCache(CacheConfig{Type: MemoryCacheType, MemoryEvictionPolicy: "unknown"}); err != ErrInvalidParameter {
+		t.Errorf("期望未知策略返回ErrInvalidParameter, 实际 %v", err)
+	}
+}
+
+// evictionTrace 用于比较命中率的访问序列
+type evictionTrace struct {
+	name string
+	keys []string
+}
+
+// syntheticTraces 生成固定种子的访问序列
+func syntheticTraces() []evictionTrace {
+	const n = 200000
+	rng := rand.New(rand.NewSource(42))
+	zipf := rand.NewZipf(rng, 1.01, 1, 50000)
+
+	zipfKeys := make([]string, n)
+	for i := range zipfKeys {
+		zipfKeys[i] = fmt.Sprintf("z%d", zipf.Uint64())
+	}
+
+	// 热点访问中夹杂一次性的长扫描
+	scanKeys := make([]string, 0, n)
+	scan := 0
+	for len(scanKeys) < n {
+		if rng.Intn(1000) == 0 {
+			for i := 0; i < 500 && len(scanKeys) < n; i++ {
+				scanKeys = append(scanKeys, fmt.Sprintf("s%d", scan))
+				scan++
+			}
+			continue
+		}
+		scanKeys = append(scanKeys, fmt.Sprintf("z%d", zipf.Uint64()))
+	}
+
+	// 循环访问略大于容量的键集合
+	loopKeys := make([]string, n)
+	for i := range loopKeys {
+		loopKeys[i] = fmt.Sprintf("l%d", i%1200)
+	}
+
+	return []evictionTrace{
+		{name: "zipf", keys: zipfKeys},
+		{name: "zipf-scan", keys: scanKeys},
+		{name: "loop", keys: loopKeys},
+	}
+}
+
+// recordedTraces 读取testdata/traces目录下记录的访问序列，每行一个键；没有找到任何序列时失败
+func recordedTraces(b *testing.B) []evictionTrace {
+	paths, _ := filepath.Glob(filepath.Join("testdata", "traces", "*.trace"))
+	if len(paths) == 0 {
+		b.Fatal("testdata/traces下没有记录的访问序列")
+	}
+	traces := make([]evictionTrace, 0, len(paths))
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			b.Fatalf("读取访问序列失败: %v", err)
+		}
+		var keys []string
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if key := strings.TrimSpace(scanner.Text()); key != "" {
+				keys = append(keys, key)
+			}
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			b.Fatalf("读取访问序列失败: %v", err)
+		}
+		traces = append(traces, evictionTrace{name: strings.TrimSuffix(filepath.Base(path), ".trace"), keys: keys})
+	}
+	return traces
+}
+
+// BenchmarkEvictionHitRatio 以容量1000回放访问序列，未命中时写入，报告各淘汰策略的命中率。
+// 除生成的序列外，还会回放testdata/traces/*.trace中记录的序列
+func BenchmarkEvictionHitRatio(b *testing.B) {
+	traces := append(syntheticTraces(), recordedTraces(b)...)
+	for _, trace := range traces {
+		for _, policyType := range evictionPolicyTypes {
+			b.Run(trace.name+"/"+string(policyType), func(b *testing.B) {
+				var hits, total int
+				for i := 0; i < b.N; i++ {
+					cache := NewMemoryCacheWithOptions(MemoryCacheOptions{MaxEntries: 1000, EvictionPolicy: policyType})
+					for _, key := range trace.keys {
+						if _, err := cache.Get(key); err == nil {
+							hits++
+						} else {
+							_ = cache.Set(key, "v", 0)
+						}
+					}
+					total += len(trace.keys)
+					cache.Close()
+				}
+				b.ReportMetric(float64(hits)/float64(total), "hit-ratio")
+			})
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package go_cache
+
+import (
+	"hash/fnv"
+)
+
+const (
+	// tinyLFUWindowPercent 窗口LRU占总容量的百分比
+	tinyLFUWindowPercent = 1
+	// tinyLFUProtectedPercent 受保护区占主区的百分比
+	tinyLFUProtectedPercent = 80
+	// tinyLFUSampleFactor 计数总和达到容量的该倍数时，所有计数减半
+	tinyLFUSampleFactor = 10
+)
+
+// tinyLFUPolicy W-TinyLFU（Einziger et al., 2017）。
+// 新键先进入窗口LRU，被挤出窗口后进入主区试用段；需要淘汰时，
+// 刚挤出窗口的键与试用段最久未访问的键比较估算的访问频率，频率更高的一方留下；主区为分段LRU，试用段中再次命中的键晋升到受保护段
+type tinyLFUPolicy struct {
+	sketch *countMinSketch
+
+	window       *lruList
+	probation    *lruList
+	protected    *lruList
+	windowCap    int
+	protectedCap int
+	candidate    string // 最近一次挤出窗口、等待准入的键
+}
+
+func newTinyLFUPolicy(capacity int) *tinyLFUPolicy {
+	windowCap := max(1, capacity*tinyLFUWindowPercent/100)
+	mainCap := max(1, capacity-windowCap)
+	return &tinyLFUPolicy{
+		sketch:       newCountMinSketch(capacity),
+		window:       newLRUList(),
+		probation:    newLRUList(),
+		protected:    newLRUList(),
+		windowCap:    windowCap,
+		protectedCap: max(1, mainCap*tinyLFUProtectedPercent/100),
+	}
+}
+
+func (t *tinyLFUPolicy) Add(key string) {
+	if t.window.has(key) || t.probation.has(key) || t.protected.has(key) {
+		t.Access(key)
+		return
+	}
+	t.sketch.increment(key)
+	t.window.add(key)
+	// 挤出窗口的键进入试用段，主区满时在Victim中决定是否准入
+	for t.window.len() > t.windowCap {
+		candidate, _ := t.window.pop()
+		t.probation.add(candidate)
+		t.candidate = candidate
+	}
+}
+
+func (t *tinyLFUPolicy) Access(key string) {
+	t.sketch.increment(key)
+	switch {
+	case t.window.has(key):
+		t.window.touch(key)
+	case t.probation.has(key):
+		t.probation.remove(key)
+		t.protected.add(key)
+		for t.protected.len() > t.protectedCap {
+			demoted, _ := t.protected.pop()
+			t.probation.add(demoted)
+		}
+	default:
+		t.protected.touch(key)
+	}
+}
+
+func (t *tinyLFUPolicy) Remove(key string) {
+	if !t.window.remove(key) && !t.probation.remove(key) {
+		t.protected.remove(key)
+	}
+}
+
+func (t *tinyLFUPolicy) Victim() (string, bool) {
+	candidate := t.candidate
+	t.candidate = ""
+	victim, ok := t.mainVictim()
+	if !ok {
+		return t.window.pop()
+	}
+	// 准入控制：刚挤出窗口的键频率不高于主区的淘汰对象时，淘汰该键本身
+	if candidate != "" && candidate != victim && t.probation.has(candidate) &&
+		t.sketch.estimate(candidate) <= t.sketch.estimate(victim) {
+		victim = candidate
+	}
+	t.Remove(victim)
+	return victim, true
+}
+
+// mainVictim 返回主区中最先被淘汰的键
+func (t *tinyLFUPolicy) mainVictim() (string, bool) {
+	if key, ok := t.probation.back(); ok {
+		return key, true
+	}
+	return t.protected.back()
+}
+
+// countMinSketchDepth count-min sketch的行数
+const countMinSketchDepth = 4
+
+// countMinSketch 估算键的访问频率，计数上限为15，
+// 累计计数达到采样上限时全部减半，使频率随时间衰减
+type countMinSketch struct {
+	rows      [countMinSketchDepth][]uint8
+	mask      uint64
+	additions int
+	sample    int
+}
+
+func newCountMinSketch(capacity int) *countMinSketch {
+	width := 16
+	for width < capacity {
+		width <<= 1
+	}
+	s := &countMinSketch{
+		mask:   uint64(width - 1),
+		sample: max(capacity, 1) * tinyLFUSampleFactor,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// indexes 使用双重哈希计算每一行的位置
+func (s *countMinSketch) indexes(key string) [countMinSketchDepth]uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	sum := h.Sum64()
+	h1, h2 := sum, sum>>32|sum<<32|1
+	var idx [countMinSketchDepth]uint64
+	for i := range idx {
+		idx[i] = (h1 + uint64(i)*h2) & s.mask
+	}
+	return idx
+}
+
+func (s *countMinSketch) increment(key string) {
+	for i, idx := range s.indexes(key) {
+		if s.rows[i][idx] < 15 {
+			s.rows[i][idx]++
+		}
+	}
+	s.additions++
+	if s.additions >= s.sample {
+		s.reset()
+	}
+}
+
+func (s *countMinSketch) estimate(key string) uint8 {
+	est := uint8(15)
+	for i, idx := range s.indexes(key) {
+		est = min(est, s.rows[i][idx])
+	}
+	return est
+}
+
+// reset 所有计数减半
+func (s *countMinSketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
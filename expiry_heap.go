@@ -0,0 +1,71 @@
+package go_cache
+
+import (
+	"container/heap"
+	"time"
+)
+
+// expiryHeap 是按过期时间排序的最小堆，只包含设置了过期时间的缓存项
+type expiryHeap []*cacheItem
+
+func (h expiryHeap) Len() int { return len(h) }
+
+func (h expiryHeap) Less(i, j int) bool { return h[i].expiration.Before(h[j].expiration) }
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expiryHeap) Push(x any) {
+	item := x.(*cacheItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *expiryHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+// add 将缓存项加入索引，没有过期时间的缓存项不加入
+func (h *expiryHeap) add(item *cacheItem) {
+	if !item.expiration.IsZero() {
+		heap.Push(h, item)
+	}
+}
+
+// remove 将缓存项移出索引
+func (h *expiryHeap) remove(item *cacheItem) {
+	if !item.expiration.IsZero() {
+		heap.Remove(h, item.index)
+	}
+}
+
+// update 修改缓存项的过期时间并调整索引
+func (h *expiryHeap) update(item *cacheItem, expiration time.Time) {
+	switch {
+	case item.expiration.IsZero():
+		item.expiration = expiration
+		h.add(item)
+	case expiration.IsZero():
+		h.remove(item)
+		item.expiration = expiration
+	default:
+		item.expiration = expiration
+		heap.Fix(h, item.index)
+	}
+}
+
+// peek 返回最早过期的缓存项
+func (h expiryHeap) peek() (*cacheItem, bool) {
+	if len(h) == 0 {
+		return nil, false
+	}
+	return h[0], true
+}
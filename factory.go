@@ -1,5 +1,7 @@
 package go_cache
 
+import "time"
+
 // CacheType 定义缓存类型
 type CacheType string
 
@@ -12,6 +14,9 @@ const (
 
 	// FileCacheType 文件缓存类型
 	FileCacheType CacheType = "file"
+
+	// DiskLogCacheType 追加写日志的磁盘缓存类型
+	DiskLogCacheType CacheType = "disklog"
 )
 
 // CacheConfig 缓存配置
@@ -25,21 +30,81 @@ type CacheConfig struct {
 	RedisDB       int
 
 	// File配置
-	FileDir string
+	FileDir             string
+	FileDurable         bool          // 每次写入后fsync所在目录
+	FileJanitorInterval time.Duration // 后台清理间隔，0表示不启动后台清理
+	FileMaxBytes        int64         // 缓存文件的总字节数上限，0表示不限制
+	FileMaxFiles        int           // 缓存文件数上限，0表示不限制
+	FileFormat          FileFormat    // 写入缓存文件的格式，默认为FileFormatJSON
+
+	// DiskLog配置
+	DiskLogDir             string
+	DiskLogDurable         bool          // 每次写入后fsync当前段
+	DiskLogSegmentSize     int64         // 单个段文件的大小上限，默认为64MB
+	DiskLogCompactInterval time.Duration // 后台合并的检查间隔，0表示不启动后台合并
+
+	// Memory配置，超出限制时按MemoryEvictionPolicy淘汰条目，0表示不限制
+	MemoryMaxEntries      int
+	MemoryMaxBytes        int64
+	MemoryEvictionPolicy  EvictionPolicyType // 默认为LRU
+	MemoryShards          int                // 分片数，0表示不分片
+	MemoryJanitorInterval time.Duration      // 过期清理间隔，默认为1秒
+	MemoryStorage         MemoryStorage      // 条目的存储方式，默认为MapStorage
 
 	PrefixKey string // 缓存key的前缀
+
+	// Codec 非字符串值的序列化方式，默认为JSONCodec
+	Codec Codec
+
+	// Clock 内存缓存、文件缓存和日志缓存使用的时钟，默认为系统时钟
+	Clock Clock
 }
 
 // NewCache 根据配置创建缓存实例
 func NewCache(config CacheConfig) (Cache, error) {
 	switch config.Type {
 	case RedisCacheType:
-		return NewRedisCache(config.RedisAddr, config.RedisPassword, config.RedisDB, config.PrefixKey), nil
+		return NewRedisCacheWithOptions(config.RedisAddr, config.RedisPassword, config.RedisDB, config.PrefixKey, RedisCacheOptions{
+			Codec: config.Codec,
+		}), nil
 	case MemoryCacheType:
-		return NewMemoryCache(), nil
+		if _, err := NewEvictionPolicy(config.MemoryEvictionPolicy, 0); err != nil {
+			return nil, err
+		}
+		return NewMemoryCacheWithOptions(config.memoryOptions()), nil
 	case FileCacheType:
-		return NewFileCache(config.FileDir)
+		return NewFileCacheWithOptions(config.FileDir, FileCacheOptions{
+			Codec:           config.Codec,
+			Clock:           config.Clock,
+			Durable:         config.FileDurable,
+			JanitorInterval: config.FileJanitorInterval,
+			MaxBytes:        config.FileMaxBytes,
+			MaxFiles:        config.FileMaxFiles,
+			Format:          config.FileFormat,
+		})
+	case DiskLogCacheType:
+		return NewDiskLogCacheWithOptions(config.DiskLogDir, DiskLogCacheOptions{
+			Codec:           config.Codec,
+			Clock:           config.Clock,
+			Durable:         config.DiskLogDurable,
+			SegmentSize:     config.DiskLogSegmentSize,
+			CompactInterval: config.DiskLogCompactInterval,
+		})
 	default:
-		return NewMemoryCache(), nil
+		return NewMemoryCacheWithOptions(config.memoryOptions()), nil
+	}
+}
+
+// memoryOptions 根据配置生成内存缓存的选项
+func (config CacheConfig) memoryOptions() MemoryCacheOptions {
+	return MemoryCacheOptions{
+		Codec:           config.Codec,
+		MaxEntries:      config.MemoryMaxEntries,
+		MaxBytes:        config.MemoryMaxBytes,
+		EvictionPolicy:  config.MemoryEvictionPolicy,
+		Shards:          config.MemoryShards,
+		JanitorInterval: config.MemoryJanitorInterval,
+		Storage:         config.MemoryStorage,
+		Clock:           config.Clock,
 	}
 }
@@ -0,0 +1,86 @@
+package go_cache
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// atomicFS 原子写入依赖的文件操作，测试时替换atomicOps以模拟写入过程中崩溃
+type atomicFS interface {
+	Write(f *os.File, data []byte) error
+	Sync(f *os.File) error
+	Rename(oldpath, newpath string) error
+	SyncDir(dir string) error
+}
+
+// osFS 直接调用操作系统的atomicFS
+type osFS struct{}
+
+func (osFS) Write(f *os.File, data []byte) error {
+	_, err := f.Write(data)
+	return err
+}
+
+func (osFS) Sync(f *os.File) error { return f.Sync() }
+
+func (osFS) Rename(oldpath, newpath string) error { return os.Rename(oldpath, newpath) }
+
+func (osFS) SyncDir(dir string) error { return syncDir(dir) }
+
+var atomicOps atomicFS = osFS{}
+
+// writeFileAtomic 先写入同一目录下的临时文件并fsync，再重命名为path，
+// 读取方和崩溃后的进程只会看到旧文件或完整的新文件。durable为true时还会fsync所在目录，
+// 确保重命名本身在断电后仍然有效
+func writeFileAtomic(path string, data []byte, durable bool) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	done := false
+	defer func() {
+		if !done {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if err := atomicOps.Write(tmp, data); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		return err
+	}
+	if err := atomicOps.Sync(tmp); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := atomicOps.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	done = true
+
+	if !durable {
+		return nil
+	}
+	return atomicOps.SyncDir(dir)
+}
+
+// syncDir fsync目录，使目录中的重命名持久化
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		// Windows不支持对目录调用fsync
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,170 @@
+package go_cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// errSimulatedCrash 模拟进程崩溃时写入返回的错误
+var errSimulatedCrash = errors.New("go_cache: simulated crash")
+
+// crashFS 在stage阶段模拟进程崩溃的atomicFS。write阶段只写入一半数据；
+// 重命名之前崩溃时另外链接一份临时文件，模拟崩溃的进程来不及清理而残留的临时文件
+type crashFS struct {
+	osFS
+	stage string
+}
+
+func (c crashFS) crash(f *os.File) error {
+	_ = os.Link(f.Name(), f.Name()+"-crash")
+	return errSimulatedCrash
+}
+
+func (c crashFS) Write(f *os.File, data []byte) error {
+	if c.stage == "write" {
+		_, _ = f.Write(data[:len(data)/2])
+		return c.crash(f)
+	}
+	return c.osFS.Write(f, data)
+}
+
+func (c crashFS) Sync(f *os.File) error {
+	if c.stage == "sync" {
+		return c.crash(f)
+	}
+	return c.osFS.Sync(f)
+}
+
+func (c crashFS) Rename(oldpath, newpath string) error {
+	if c.stage == "rename" {
+		_ = os.Link(oldpath, oldpath+"-crash")
+		return errSimulatedCrash
+	}
+	return c.osFS.Rename(oldpath, newpath)
+}
+
+func (c crashFS) SyncDir(dir string) error {
+	if c.stage == "syncdir" {
+		return errSimulatedCrash
+	}
+	return c.osFS.SyncDir(dir)
+}
+
+// setAtomicFS 替换原子写入使用的文件操作，返回恢复原值的函数
+func setAtomicFS(fs atomicFS) func() {
+	prev := atomicOps
+	atomicOps = fs
+	return func() { atomicOps = prev }
+}
+
+func TestFileCache_CrashDuringWrite(t *testing.T) {
+	defer Init()()
+
+	// 崩溃发生在重命名之前时保留旧值，之后时为新值
+	for stage, want := range map[string]string{
+		"write":   "old",
+		"sync":    "old",
+		"rename":  "old",
+		"syncdir": "new",
+	} {
+		t.Run(stage, func(t *testing.T) {
+			clearTestFile()
+			cache, err := NewFileCacheWithOptions(testFilePath, FileCacheOptions{Durable: true})
+			if err != nil {
+				t.Fatalf("创建文件缓存失败: %v", err)
+			}
+			_ = cache.Set("k", "old", 0)
+
+			restore := setAtomicFS(crashFS{stage: stage})
+			err = cache.Set("k", "new", 0)
+			restore()
+			if !errors.Is(err, errSimulatedCrash) {
+				t.Fatalf("期望写入在 %s 阶段崩溃, 实际 %v", stage, err)
+			}
+
+			// 重启后读取
+			restarted, _ := NewFileCache(testFilePath)
+			if got, err := restarted.Get("k"); got != want {
+				t.Errorf("期望崩溃后读到 %s, 实际 %q %v", want, got, err)
+			}
+			if exists, _ := restarted.Exists("k"); !exists {
+				t.Error("期望崩溃后缓存文件仍然存在")
+			}
+			// 残留的临时文件不会被当作缓存项
+			if keys, _ := restarted.Keys("*"); len(keys) != 1 {
+				t.Errorf("期望只枚举到1个键, 实际 %v", keys)
+			}
+		})
+	}
+}
+
+func TestFileCache_NoTempFilesLeft(t *testing.T) {
+	defer Init()()
+	cache, err := NewFileCache(testFilePath)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	_ = cache.Set("k", "v", 0)
+	_ = cache.Set("k", "v2", time.Minute)
+	_ = cache.Expire("k", time.Hour)
+
+	// 正常写入不残留临时文件
+	var tmpFiles []string
+	_ = filepath.Walk(testFilePath, func(path string, info os.FileInfo, err error) error {
+		if err == nil && strings.Contains(info.Name(), ".tmp-") {
+			tmpFiles = append(tmpFiles, path)
+		}
+		return nil
+	})
+	if len(tmpFiles) != 0 {
+		t.Errorf("期望没有残留的临时文件, 实际 %v", tmpFiles)
+	}
+	info, err := os.Stat(cache.getFilePath("k"))
+	if err != nil || info.Mode().Perm() != 0644 {
+		t.Errorf("期望缓存文件权限为0644, 实际 %v %v", info.Mode(), err)
+	}
+}
+
+func TestFileCache_ConcurrentReadDuringWrite(t *testing.T) {
+	defer Init()()
+	cache, err := NewFileCache(testFilePath)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	large := strings.Repeat("x", 256<<10)
+	_ = cache.Set("k", large, 0)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			_ = cache.Set("k", large[i%2:], 0)
+		}
+	}()
+
+	// 读取方只会看到完整的旧文件或新文件，不会把写了一半的文件当作损坏而删除
+	for i := 0; i < 50; i++ {
+		if _, err := cache.getItem(cache.getFilePath("k"), nil); err != nil {
+			t.Errorf("期望读取完整的文件, 实际 %v", err)
+			break
+		}
+		if exists, _ := cache.Exists("k"); !exists {
+			t.Error("期望并发写入时键始终存在")
+			break
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
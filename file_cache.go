@@ -1,213 +1,515 @@
 package go_cache
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // FileCache 实现了基于文件系统的缓存
 type FileCache struct {
-	dir string
+	dir       string
+	codec     Codec
+	locks     [fileLockStripes]sync.Mutex // 按键哈希分段的锁
+	lockFiles [fileLockStripes]*os.File   // 各分段的锁文件，由对应分段的进程内锁保护
+	notifier  *evictNotifier
+	clock     Clock
+	durable   bool
+	maxBytes  int64
+	maxFiles  int
+	format    FileFormat
+	stop      func() // 停止后台清理
+}
+
+// fileLockStripes 文件缓存锁的分段数
+const fileLockStripes = 64
+
+// fileLockDir 锁文件所在的子目录，名称不是两位十六进制，不会被键枚举遍历到；Clear不会删除该目录
+const fileLockDir = "locks"
+
+// FileCacheOptions 文件缓存的可选配置
+type FileCacheOptions struct {
+	// Codec 非字符串值的序列化方式，默认为JSONCodec
+	Codec Codec
+
+	// Clock 读取当前时间的时钟，默认为系统时钟，测试时可以使用FakeClock
+	Clock Clock
+
+	// Durable 为true时每次写入后还会fsync所在目录，保证断电后写入仍然有效，代价是更慢的写入。
+	// 无论是否开启，写入都先写临时文件再重命名，读取方不会看到写了一半的文件
+	Durable bool
+
+	// JanitorInterval 后台调用Sweep的间隔，0表示不启动后台清理，过期文件只在被读取时删除
+	JanitorInterval time.Duration
+
+	// MaxBytes、MaxFiles 缓存文件的总字节数和文件数上限，0表示不限制。
+	// 由Sweep检查，超出时淘汰最久未写入或Get命中的文件，两次清理之间可能暂时超出
+	MaxBytes int64
+	MaxFiles int
+
+	// Format 写入缓存文件的格式，默认为FileFormatJSON；读取时自动识别两种格式，
+	// 可以用Migrate把已有的文件改写为该格式
+	Format FileFormat
 }
 
 // fileItem 表示文件缓存中的一个项目
 type fileItem struct {
+	Key        string    `json:"key,omitempty"` // 原始键，用于键枚举；旧版本写入的文件没有该字段
 	Value      string    `json:"value"`
+	Data       []byte    `json:"data,omitempty"` // 值不是合法UTF-8时（如gob编码）以base64保存
 	Expiration time.Time `json:"expiration"`
+	Tags       []string  `json:"tags,omitempty"`
+	// Sliding 滑动过期时长，大于0时读取会延长过期时间
+	Sliding time.Duration `json:"sliding,omitempty"`
+}
+
+// hasTag 判断缓存项是否关联了tag
+func (item *fileItem) hasTag(tag string) bool {
+	for _, t := range item.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// newFileItem 创建文件缓存项，非UTF-8的值保存在Data中以免被JSON编码破坏
+func newFileItem(key string, value string, expiration time.Time) *fileItem {
+	if !utf8.ValidString(value) {
+		return &fileItem{Key: key, Data: []byte(value), Expiration: expiration}
+	}
+	return &fileItem{Key: key, Value: value, Expiration: expiration}
+}
+
+// value 返回缓存项保存的值
+func (item *fileItem) value() string {
+	if item.Data != nil {
+		return string(item.Data)
+	}
+	return item.Value
 }
 
 // NewFileCache 创建一个新的文件系统缓存实例
 func NewFileCache(dir string) (*FileCache, error) {
+	return NewFileCacheWithOptions(dir, FileCacheOptions{})
+}
+
+// NewFileCacheWithOptions 使用指定配置创建一个新的文件系统缓存实例
+func NewFileCacheWithOptions(dir string, opts FileCacheOptions) (*FileCache, error) {
 	// 确保目录存在
 	err := os.MkdirAll(dir, 0755)
 	if err != nil {
 		return nil, err
 	}
 
-	return &FileCache{
-		dir: dir,
-	}, nil
+	if opts.Codec == nil {
+		opts.Codec = JSONCodec{}
+	}
+	if opts.Format == "" {
+		opts.Format = FileFormatJSON
+	}
+	if !validFileFormat(opts.Format) {
+		return nil, fmt.Errorf("%w: unknown file format %q", ErrInvalidParameter, opts.Format)
+	}
+	cache := &FileCache{
+		dir:      dir,
+		codec:    opts.Codec,
+		notifier: &evictNotifier{},
+		clock:    clockOrSystem(opts.Clock),
+		durable:  opts.Durable,
+		maxBytes: opts.MaxBytes,
+		maxFiles: opts.MaxFiles,
+		format:   opts.Format,
+		stop:     func() {},
+	}
+	if opts.JanitorInterval > 0 {
+		cache.stop = cache.clock.Every(opts.JanitorInterval, func() {
+			_ = cache.Sweep()
+		})
+	}
+	return cache, nil
 }
 
 // Set 将键值对存储到缓存中，并设置过期时间
 func (f *FileCache) Set(key string, value interface{}, expiration time.Duration) error {
-	var expirationTime time.Time
-	if expiration > 0 {
-		expirationTime = time.Now().Add(expiration)
-	}
-
-	item := &fileItem{
-		Value:      ToString(value),
-		Expiration: expirationTime,
+	str, err := encodeValue(f.codec, value)
+	if err != nil {
+		return err
 	}
 
-	data, err := json.Marshal(item)
+	ev := f.notifier.collect()
+	defer ev.flush()
+	var stale []string
+	defer func() { f.pruneTagIndex(key, stale) }()
+	unlock, err := f.lockKey(key)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
 	filePath := f.getFilePath(key)
-	// 检查文件夹是否存在，不存在就创建
-	if _, err = os.Stat(filepath.Dir(filePath)); os.IsNotExist(err) {
-		if err = os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
-			return err
-		}
-	}
-	return os.WriteFile(filePath, data, 0644)
+	stale = f.noteRemoval(key, filePath, EvictReasonReplaced, ev)
+	return f.writeItem(filePath, newFileItem(key, str, expireAt(f.clock.Now(), expiration)))
 }
 
 // Get 从缓存中获取指定键的值
 func (f *FileCache) Get(key string) (string, error) {
+	ev := f.notifier.collect()
+	defer ev.flush()
 	filePath := f.getFilePath(key)
-
-	// 检查文件是否存在
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return "", ErrKeyNotFound
+	item, err := f.getItem(filePath, ev)
+	if err != nil {
+		return "", err
 	}
+	f.markAccess(filePath)
+	if item.Sliding > 0 {
+		item, err = f.touch(key, ev, func(item *fileItem) (time.Duration, bool) {
+			return item.Sliding, item.Sliding > 0
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return item.value(), nil
+}
 
-	data, err := os.ReadFile(filePath)
+// GetAndTouch 获取指定键的值，并将过期时间重置为ttl，ttl<=0表示永不过期
+func (f *FileCache) GetAndTouch(key string, ttl time.Duration) (string, error) {
+	ev := f.notifier.collect()
+	defer ev.flush()
+	item, err := f.touch(key, ev, func(item *fileItem) (time.Duration, bool) {
+		return ttl, true
+	})
 	if err != nil {
 		return "", err
 	}
+	return item.value(), nil
+}
 
-	var item fileItem
-	err = json.Unmarshal(data, &item)
+// SetSliding 写入滑动过期的键值对，之后每次Get或GetMulti命中都将过期时间重置为读取时刻加ttl。
+// 为了避免每次读取都重写文件，过期时间只延长不到ttl/10时不写回，因此条目最多可能提前ttl/10过期
+func (f *FileCache) SetSliding(key string, value interface{}, ttl time.Duration) error {
+	str, err := encodeValue(f.codec, value)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	// 检查是否过期
-	if !item.Expiration.IsZero() && time.Now().After(item.Expiration) {
-		// 删除过期文件
-		os.Remove(filePath)
-		return "", ErrKeyNotFound
+	ev := f.notifier.collect()
+	defer ev.flush()
+	var stale []string
+	defer func() { f.pruneTagIndex(key, stale) }()
+	unlock, err := f.lockKey(key)
+	if err != nil {
+		return err
 	}
+	defer unlock()
 
-	return item.Value, nil
+	filePath := f.getFilePath(key)
+	stale = f.noteRemoval(key, filePath, EvictReasonReplaced, ev)
+	item := newFileItem(key, str, expireAt(f.clock.Now(), ttl))
+	item.Sliding = max(ttl, 0)
+	return f.writeItem(filePath, item)
 }
 
-// Delete 从缓存中删除指定键
-func (f *FileCache) Delete(key string) error {
+// fileTouchSlack 过期时间的变化不超过ttl/fileTouchSlack时不重写文件
+const fileTouchSlack = 10
+
+// touch 在键锁内重新读取缓存项，并将过期时间重置为ttl(item)返回的时长，第二个返回值为false时不修改
+func (f *FileCache) touch(key string, ev *evictEvents, ttl func(item *fileItem) (time.Duration, bool)) (*fileItem, error) {
+	unlock, err := f.lockKey(key)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	filePath := f.getFilePath(key)
+	item, err := f.readItem(filePath, ev)
+	if err != nil {
+		return nil, err
+	}
+	d, ok := ttl(item)
+	if !ok {
+		return item, nil
+	}
+	expiration := expireAt(f.clock.Now(), d)
+	if !needsTouch(item.Expiration, expiration, d) {
+		return item, nil
+	}
+	item.Expiration = expiration
+	return item, f.writeItem(filePath, item)
+}
 
-	// 检查文件是否存在
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil // 文件不存在，认为删除成功
+// needsTouch 判断过期时间从current改为next时是否需要写回。缩短过期时间或在永不过期之间切换时总是写回，
+// 延长不超过ttl/fileTouchSlack时不写回
+func needsTouch(current, next time.Time, ttl time.Duration) bool {
+	if current.IsZero() || next.IsZero() {
+		return !current.Equal(next)
 	}
+	diff := next.Sub(current)
+	return diff < 0 || diff > ttl/fileTouchSlack
+}
 
-	return os.Remove(filePath)
+// Delete 从缓存中删除指定键
+func (f *FileCache) Delete(key string) error {
+	ev := f.notifier.collect()
+	defer ev.flush()
+	var stale []string
+	defer func() { f.pruneTagIndex(key, stale) }()
+	unlock, err := f.lockKey(key)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	filePath := f.getFilePath(key)
+	stale = f.noteRemoval(key, filePath, EvictReasonDeleted, ev)
+	return f.removeItemFiles(filePath)
 }
 
 // Exists 检查指定键是否存在于缓存中
 func (f *FileCache) Exists(key string) (bool, error) {
+	ev := f.notifier.collect()
+	defer ev.flush()
 	filePath := f.getFilePath(key)
-
-	// 检查文件是否存在
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	_, err := f.getItem(filePath, ev)
+	if errors.Is(err, ErrKeyNotFound) {
 		return false, nil
 	}
-
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return false, err
-	}
-
-	var item fileItem
-	err = json.Unmarshal(data, &item)
-	if err != nil {
+	if errors.Is(err, ErrCorrupted) {
 		// 数据损坏，删除文件
-		os.Remove(filePath)
-		return false, nil
+		return false, f.removeCorrupted(key, filePath)
 	}
-
-	// 检查是否过期
-	if !item.Expiration.IsZero() && time.Now().After(item.Expiration) {
-		// 删除过期文件
-		os.Remove(filePath)
-		return false, nil
+	if err != nil {
+		return false, err
 	}
-
 	return true, nil
 }
 
 // Expire 设置键的过期时间
 func (f *FileCache) Expire(key string, expiration time.Duration) error {
-	filePath := f.getFilePath(key)
-
-	// 检查文件是否存在
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return ErrKeyNotFound
-	}
-
-	data, err := os.ReadFile(filePath)
+	ev := f.notifier.collect()
+	defer ev.flush()
+	unlock, err := f.lockKey(key)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	var item fileItem
-	err = json.Unmarshal(data, &item)
+	filePath := f.getFilePath(key)
+	item, err := f.readItem(filePath, ev)
 	if err != nil {
 		return err
 	}
 
 	if expiration > 0 {
-		item.Expiration = time.Now().Add(expiration)
+		item.Expiration = f.clock.Now().Add(expiration)
 	} else {
 		item.Expiration = time.Time{}
 	}
 
 	// 保存更新后的项
-	newData, err := json.Marshal(item)
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(filePath, newData, 0644)
+	return f.writeItem(filePath, item)
 }
 
 // TTL 获取键的剩余生存时间
 func (f *FileCache) TTL(key string) (time.Duration, error) {
-	filePath := f.getFilePath(key)
+	ev := f.notifier.collect()
+	defer ev.flush()
+	item, err := f.getItem(f.getFilePath(key), ev)
+	if err != nil {
+		return 0, err
+	}
+
+	if item.Expiration.IsZero() {
+		// 永不过期
+		return -1, nil
+	}
 
-	// 检查文件是否存在
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return 0, ErrKeyNotFound
+	return item.Expiration.Sub(f.clock.Now()), nil
+}
+
+// getItem 在不持有键锁时读取缓存项，返回值与readItem相同；
+// 过期的文件交给removeExpired在键锁内确认后删除，不会误删并发写入的新值
+func (f *FileCache) getItem(filePath string, ev *evictEvents) (*fileItem, error) {
+	filePath, data, err := readItemFile(filePath)
+	if os.IsNotExist(err) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	item, err := decodeFileItem(data)
+	if err != nil {
+		return nil, err
+	}
+	if !item.Expiration.IsZero() && f.clock.Now().After(item.Expiration) {
+		if err := f.removeExpired(item.Key, filePath, ev); err != nil {
+			return nil, err
+		}
+		return nil, ErrKeyNotFound
 	}
+	return item, nil
+}
 
-	data, err := os.ReadFile(filePath)
+// readItem 读取并解析缓存文件，文件不存在或已过期时返回ErrKeyNotFound，过期文件会被删除并记录到ev，
+// 因此调用方必须持有键锁，否则使用getItem；
+// 文件无法解析或校验和不匹配时返回ErrCorrupted，格式版本不受支持时返回ErrUnsupportedFormat
+func (f *FileCache) readItem(filePath string, ev *evictEvents) (*fileItem, error) {
+	filePath, data, err := readItemFile(filePath)
+	if os.IsNotExist(err) {
+		return nil, ErrKeyNotFound
+	}
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	var item fileItem
-	err = json.Unmarshal(data, &item)
+	item, err := decodeFileItem(data)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	// 检查是否过期
-	if !item.Expiration.IsZero() && time.Now().After(item.Expiration) {
+	if !item.Expiration.IsZero() && f.clock.Now().After(item.Expiration) {
 		// 删除过期文件
-		os.Remove(filePath)
-		return 0, ErrKeyNotFound
+		if os.Remove(filePath) == nil && item.Key != "" {
+			ev.add(item.Key, item.value(), EvictReasonExpired)
+		}
+		return nil, ErrKeyNotFound
 	}
 
-	if item.Expiration.IsZero() {
-		// 永不过期
-		return -1, nil
+	return item, nil
+}
+
+// writeItem 将缓存项写入文件，必要时创建子目录，成功后删除该键另一种扩展名的旧文件
+func (f *FileCache) writeItem(filePath string, item *fileItem) error {
+	data, err := encodeFileItem(item, f.format)
+	if err != nil {
+		return err
+	}
+
+	// 检查文件夹是否存在，不存在就创建
+	if _, err = os.Stat(filepath.Dir(filePath)); os.IsNotExist(err) {
+		if err = os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return err
+		}
+	}
+	err = writeFileAtomic(filePath, data, f.durable)
+	if errors.Is(err, os.ErrNotExist) {
+		// 后台清理可能恰好删除了空的子目录，重建后重试一次
+		if err = os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return err
+		}
+		err = writeFileAtomic(filePath, data, f.durable)
 	}
+	if err != nil {
+		return err
+	}
+	return f.removeFile(otherItemPath(filePath))
+}
+
+// removeFile 删除缓存文件，文件不存在时认为删除成功
+func (f *FileCache) removeFile(filePath string) error {
+	err := os.Remove(filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
 
-	return time.Until(item.Expiration), nil
+// removeItemFiles 删除键的两种扩展名的缓存文件
+func (f *FileCache) removeItemFiles(filePath string) error {
+	if err := f.removeFile(filePath); err != nil {
+		return err
+	}
+	return f.removeFile(otherItemPath(filePath))
+}
+
+// noteRemoval 读取即将被覆盖或删除的缓存项并记录事件，返回它关联的标签，调用方释放键锁后用于清理标签索引
+func (f *FileCache) noteRemoval(key, filePath string, reason EvictReason, ev *evictEvents) []string {
+	item, err := f.readItem(filePath, ev)
+	if err != nil {
+		return nil
+	}
+	ev.add(key, item.value(), reason)
+	return item.Tags
+}
+
+// OnEvict 注册监听函数，缓存项因过期、删除或覆盖离开缓存时调用，调用时不持有键锁。
+// 过期的文件在被读取或遍历到时才会删除并通知；有监听函数时覆盖和删除前需要额外读取一次旧文件
+func (f *FileCache) OnEvict(fn func(key, value string, reason EvictReason)) {
+	f.notifier.add(fn)
+}
+
+// lockKey 对键所在的分段先加进程内锁，再对分段的锁文件加flock，保证读-改-写操作在同一主机的多个进程之间也是原子的，返回解锁函数。
+// 不支持flock的平台上只有进程内锁
+func (f *FileCache) lockKey(key string) (func(), error) {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	stripe := h.Sum32() % fileLockStripes
+	mu := &f.locks[stripe]
+	mu.Lock()
+
+	lf, err := f.lockFile(stripe)
+	if err == nil {
+		err = lockFile(lf)
+	}
+	if err != nil {
+		mu.Unlock()
+		return nil, err
+	}
+	return func() {
+		_ = unlockFile(lf)
+		mu.Unlock()
+	}, nil
+}
+
+// lockFile 返回分段对应的锁文件，第一次使用时打开并保持打开，调用方必须持有该分段的进程内锁
+func (f *FileCache) lockFile(stripe uint32) (*os.File, error) {
+	if lf := f.lockFiles[stripe]; lf != nil {
+		return lf, nil
+	}
+	dir := filepath.Join(f.dir, fileLockDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	lf, err := os.OpenFile(filepath.Join(dir, fmt.Sprintf("%02x.lock", stripe)), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	f.lockFiles[stripe] = lf
+	return lf, nil
+}
+
+// Codec 返回缓存使用的序列化方式
+func (f *FileCache) Codec() Codec {
+	return f.codec
 }
 
 // Close 关闭缓存连接
 func (f *FileCache) Close() error {
-	// 文件系统缓存不需要特殊关闭操作
+	f.stop()
+	// 关闭锁文件，之后再次加锁时会重新打开
+	for i := range f.locks {
+		f.locks[i].Lock()
+		if lf := f.lockFiles[i]; lf != nil {
+			lf.Close()
+			f.lockFiles[i] = nil
+		}
+		f.locks[i].Unlock()
+	}
 	return nil
 }
 
@@ -223,6 +525,573 @@ func (f *FileCache) getFilePath(key string) string {
 	subDir2 := hash[2:4]
 
 	// 创建完整的文件路径
-	filename := fmt.Sprintf("%s.json", hash)
+	filename := hash + fileExt(f.format)
 	return filepath.Join(f.dir, subDir+"/"+subDir2, filename)
 }
+
+// SetCtx 将键值对存储到缓存中，ctx已取消时直接返回
+func (f *FileCache) SetCtx(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.Set(key, value, expiration)
+}
+
+// GetCtx 从缓存中获取指定键的值，ctx已取消时直接返回
+func (f *FileCache) GetCtx(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return f.Get(key)
+}
+
+// DeleteCtx 从缓存中删除指定键，ctx已取消时直接返回
+func (f *FileCache) DeleteCtx(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.Delete(key)
+}
+
+// ExistsCtx 检查指定键是否存在于缓存中，ctx已取消时直接返回
+func (f *FileCache) ExistsCtx(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return f.Exists(key)
+}
+
+// ExpireCtx 设置键的过期时间，ctx已取消时直接返回
+func (f *FileCache) ExpireCtx(ctx context.Context, key string, expiration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.Expire(key, expiration)
+}
+
+// TTLCtx 获取键的剩余生存时间，ctx已取消时直接返回
+func (f *FileCache) TTLCtx(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return f.TTL(key)
+}
+
+// fileBatchConcurrency 文件缓存批量操作的并发度
+const fileBatchConcurrency = 8
+
+// GetMulti 并行读取多个键对应的文件
+func (f *FileCache) GetMulti(keys []string) (map[string]string, error) {
+	var mu sync.Mutex
+	values := make(map[string]string, len(keys))
+	errs := parallelEach(keys, fileBatchConcurrency, func(key string) error {
+		value, err := f.Get(key)
+		if errors.Is(err, ErrKeyNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		values[key] = value
+		mu.Unlock()
+		return nil
+	})
+	return values, newBatchError(errs)
+}
+
+// SetMulti 并行写入多个键对应的文件
+func (f *FileCache) SetMulti(items map[string]interface{}, expiration time.Duration) error {
+	errs := make(map[string]error)
+	encoded := encodeItems(f.codec, items, errs)
+
+	keys := make([]string, 0, len(encoded))
+	for key := range encoded {
+		keys = append(keys, key)
+	}
+	for key, err := range parallelEach(keys, fileBatchConcurrency, func(key string) error {
+		return f.Set(key, encoded[key], expiration)
+	}) {
+		errs[key] = err
+	}
+	return newBatchError(errs)
+}
+
+// DeleteMulti 并行删除多个键对应的文件
+func (f *FileCache) DeleteMulti(keys []string) error {
+	return newBatchError(parallelEach(keys, fileBatchConcurrency, f.Delete))
+}
+
+// Incr 将计数器加1
+func (f *FileCache) Incr(key string, expiration time.Duration) (int64, error) {
+	return f.IncrBy(key, 1, expiration)
+}
+
+// Decr 将计数器减1
+func (f *FileCache) Decr(key string, expiration time.Duration) (int64, error) {
+	return f.IncrBy(key, -1, expiration)
+}
+
+// DecrBy 将计数器减delta
+func (f *FileCache) DecrBy(key string, delta int64, expiration time.Duration) (int64, error) {
+	if delta == math.MinInt64 {
+		return 0, ErrNotInteger
+	}
+	return f.IncrBy(key, -delta, expiration)
+}
+
+// IncrBy 在键锁内读取、修改并写回计数器，计数器不存在时以expiration创建
+func (f *FileCache) IncrBy(key string, delta int64, expiration time.Duration) (int64, error) {
+	ev := f.notifier.collect()
+	defer ev.flush()
+	unlock, err := f.lockKey(key)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	filePath := f.getFilePath(key)
+	item, err := f.readItem(filePath, ev)
+	if errors.Is(err, ErrKeyNotFound) {
+		var expirationTime time.Time
+		if expiration > 0 {
+			expirationTime = f.clock.Now().Add(expiration)
+		}
+		return delta, f.writeItem(filePath, newFileItem(key, strconv.FormatInt(delta, 10), expirationTime))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	old := item.value()
+	n, err := incrValue(old, delta)
+	if err != nil {
+		return 0, err
+	}
+	item.Value, item.Data = strconv.FormatInt(n, 10), nil
+	if err := f.writeItem(filePath, item); err != nil {
+		return 0, err
+	}
+	ev.add(key, old, EvictReasonReplaced)
+	return n, nil
+}
+
+// SetNX 仅当键不存在时写入
+func (f *FileCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return f.setIf(key, value, expiration, func(item *fileItem) bool {
+		return item == nil
+	})
+}
+
+// SetXX 仅当键已存在时写入
+func (f *FileCache) SetXX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return f.setIf(key, value, expiration, func(item *fileItem) bool {
+		return item != nil
+	})
+}
+
+// CompareAndSwap 仅当键的当前值等于old时写入new
+func (f *FileCache) CompareAndSwap(key string, old, new interface{}, expiration time.Duration) (bool, error) {
+	oldStr, err := encodeValue(f.codec, old)
+	if err != nil {
+		return false, err
+	}
+	return f.setIf(key, new, expiration, func(item *fileItem) bool {
+		return item != nil && item.value() == oldStr
+	})
+}
+
+// setIf 在键锁内检查条件，条件满足时写入；键不存在时cond的参数为nil
+func (f *FileCache) setIf(key string, value interface{}, expiration time.Duration, cond func(item *fileItem) bool) (bool, error) {
+	str, err := encodeValue(f.codec, value)
+	if err != nil {
+		return false, err
+	}
+
+	ev := f.notifier.collect()
+	defer ev.flush()
+	var stale []string
+	defer func() { f.pruneTagIndex(key, stale) }()
+	unlock, err := f.lockKey(key)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	filePath := f.getFilePath(key)
+	item, err := f.readItem(filePath, ev)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return false, err
+	}
+	if !cond(item) {
+		return false, nil
+	}
+
+	var expirationTime time.Time
+	if expiration > 0 {
+		expirationTime = f.clock.Now().Add(expiration)
+	}
+	if err := f.writeItem(filePath, newFileItem(key, str, expirationTime)); err != nil {
+		return false, err
+	}
+	if item != nil {
+		ev.add(key, item.value(), EvictReasonReplaced)
+		stale = item.Tags
+	}
+	return true, nil
+}
+
+// Keys 返回匹配pattern的所有未过期键，旧版本写入的没有保存原始键的文件会被忽略
+func (f *FileCache) Keys(pattern string) ([]string, error) {
+	return collectKeys(f.scan(func(key string) bool {
+		return matchPattern(pattern, key)
+	}))
+}
+
+// Scan 返回以prefix开头的键的迭代器，按需逐个目录读取
+func (f *FileCache) Scan(prefix string) KeyIterator {
+	return f.scan(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+func (f *FileCache) scan(match func(key string) bool) *fileKeyIterator {
+	return &fileKeyIterator{
+		f:     f,
+		match: match,
+		dirs:  []fileScanDir{{path: f.dir}},
+	}
+}
+
+// fileScanDir 表示一个待遍历的目录，depth为相对缓存根目录的层级
+type fileScanDir struct {
+	path  string
+	depth int
+}
+
+// fileKeyIterator 按需遍历两级哈希目录下的缓存文件
+type fileKeyIterator struct {
+	f     *FileCache
+	match func(key string) bool
+	dirs  []fileScanDir
+	files []string
+	key   string
+	err   error
+}
+
+func (it *fileKeyIterator) Next() bool {
+	for it.err == nil {
+		if len(it.files) > 0 {
+			filePath := it.files[0]
+			it.files = it.files[1:]
+
+			ev := it.f.notifier.collect()
+			item, err := it.f.getItem(filePath, ev)
+			ev.flush()
+			if errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrCorrupted) || errors.Is(err, ErrUnsupportedFormat) {
+				continue
+			}
+			if err != nil {
+				it.err = err
+				return false
+			}
+			if item.Key == "" || !it.match(item.Key) {
+				continue
+			}
+			it.key = item.Key
+			return true
+		}
+
+		if len(it.dirs) == 0 {
+			return false
+		}
+		dir := it.dirs[0]
+		it.dirs = it.dirs[1:]
+		it.readDir(dir)
+	}
+	return false
+}
+
+// readDir 读取目录，缓存根目录和一级目录下只遍历哈希子目录，二级目录下只读取缓存文件
+func (it *fileKeyIterator) readDir(dir fileScanDir) {
+	entries, err := os.ReadDir(dir.path)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		it.err = err
+		return
+	}
+	names := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		names[entry.Name()] = true
+	}
+	for _, entry := range entries {
+		entryPath := filepath.Join(dir.path, entry.Name())
+		if dir.depth < 2 {
+			if entry.IsDir() && isHashDir(entry.Name()) {
+				it.dirs = append(it.dirs, fileScanDir{path: entryPath, depth: dir.depth + 1})
+			}
+			continue
+		}
+		if !entry.IsDir() && isItemFile(entry.Name()) && !it.f.supersededItemFile(entry.Name(), names) {
+			it.files = append(it.files, entryPath)
+		}
+	}
+}
+
+func (it *fileKeyIterator) Key() string {
+	return it.key
+}
+
+func (it *fileKeyIterator) Err() error {
+	return it.err
+}
+
+// supersededItemFile 同一个键两种扩展名的文件同时存在时（写入后删除旧文件之前崩溃），
+// 与读取时一样以当前扩展名的文件为准，另一个文件视为已被取代，names为同一目录下的所有文件名
+func (f *FileCache) supersededItemFile(name string, names map[string]bool) bool {
+	return filepath.Ext(name) != fileExt(f.format) && names[filepath.Base(otherItemPath(name))]
+}
+
+// isHashDir 判断目录名是否为getFilePath生成的两位十六进制子目录
+func isHashDir(name string) bool {
+	if len(name) != 2 {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Clear 删除缓存目录下的所有内容，缓存目录本身保留。有监听函数时会先读取所有缓存文件用于通知
+func (f *FileCache) Clear() error {
+	ev := f.notifier.collect()
+	defer ev.flush()
+	if ev.enabled() {
+		for it := f.scan(func(string) bool { return true }); it.Next(); {
+			f.noteRemoval(it.Key(), f.getFilePath(it.Key()), EvictReasonDeleted, ev)
+		}
+	}
+
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == fileLockDir {
+			// 其他进程可能正持有锁文件上的flock，删除后新打开的锁文件与之互不排斥
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(f.dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileTagDir 标签索引所在的子目录，名称不是两位十六进制，不会被键枚举遍历到
+const fileTagDir = "tags"
+
+// fileTagIndex 表示磁盘上一个标签的索引文件
+type fileTagIndex struct {
+	Tag  string   `json:"tag"`
+	Keys []string `json:"keys"`
+}
+
+// SetWithTags 将键值对存储到缓存中，并把键加入每个标签的磁盘索引
+func (f *FileCache) SetWithTags(key string, value interface{}, expiration time.Duration, tags ...string) error {
+	str, err := encodeValue(f.codec, value)
+	if err != nil {
+		return err
+	}
+
+	tags = uniqueKeys(append([]string(nil), tags...))
+	item := newFileItem(key, str, expireAt(f.clock.Now(), expiration))
+	item.Tags = tags
+
+	ev := f.notifier.collect()
+	defer ev.flush()
+	unlock, err := f.lockKey(key)
+	if err != nil {
+		return err
+	}
+	filePath := f.getFilePath(key)
+	oldTags := f.noteRemoval(key, filePath, EvictReasonReplaced, ev)
+	err = f.writeItem(filePath, item)
+	unlock()
+	if err != nil {
+		return err
+	}
+	f.pruneTagIndex(key, oldTags)
+
+	for _, tag := range tags {
+		if err := f.updateTagIndex(tag, func(keys []string) []string {
+			for _, k := range keys {
+				if k == key {
+					return keys
+				}
+			}
+			return append(keys, key)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InvalidateTag 删除标签索引中记录的、当前仍关联该标签的所有键
+func (f *FileCache) InvalidateTag(tag string) error {
+	var keys []string
+	if err := f.updateTagIndex(tag, func(old []string) []string {
+		keys = old
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := f.deleteIfTagged(key, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TagKeys 返回关联了tag的所有未过期键
+func (f *FileCache) TagKeys(tag string) ([]string, error) {
+	index, err := f.readTagIndex(f.getTagPath(tag))
+	if err != nil {
+		return nil, err
+	}
+	ev := f.notifier.collect()
+	defer ev.flush()
+	var keys []string
+	for _, key := range index.Keys {
+		item, err := f.getItem(f.getFilePath(key), ev)
+		if err == nil && item.hasTag(tag) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// deleteIfTagged 键仍关联tag时删除，避免误删之后被重新写入且不再带该标签的键
+func (f *FileCache) deleteIfTagged(key string, tag string) error {
+	ev := f.notifier.collect()
+	defer ev.flush()
+	var stale []string
+	defer func() { f.pruneTagIndex(key, stale) }()
+	unlock, err := f.lockKey(key)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	filePath := f.getFilePath(key)
+	item, err := f.readItem(filePath, ev)
+	if errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrCorrupted) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !item.hasTag(tag) {
+		return nil
+	}
+	if err := f.removeItemFiles(filePath); err != nil {
+		return err
+	}
+	ev.add(key, item.value(), EvictReasonDeleted)
+	// tag的索引已由InvalidateTag删除，只需清理其他标签
+	for _, t := range item.Tags {
+		if t != tag {
+			stale = append(stale, t)
+		}
+	}
+	return nil
+}
+
+// updateTagIndex 在标签锁内读取、修改并写回标签索引，update返回空时删除索引文件
+func (f *FileCache) updateTagIndex(tag string, update func(keys []string) []string) error {
+	unlock, err := f.lockKey(fileTagDir + "\x00" + tag)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tagPath := f.getTagPath(tag)
+	index, err := f.readTagIndex(tagPath)
+	if err != nil {
+		return err
+	}
+
+	index.Tag = tag
+	n := len(index.Keys)
+	index.Keys = update(index.Keys)
+	if len(index.Keys) == 0 {
+		return f.removeFile(tagPath)
+	}
+	if len(index.Keys) == n {
+		// 添加已存在的键或没有可清理的键，不重写索引
+		return nil
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(tagPath), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(tagPath, data, f.durable)
+}
+
+// pruneTagIndex 从tags的标签索引中删除key，键当前仍关联该标签时保留（可能已被其他进程重新写入）。
+// 在键锁之外调用；标签索引只是候选列表，清理失败不影响TagKeys和InvalidateTag的结果，Sweep会再次清理
+func (f *FileCache) pruneTagIndex(key string, tags []string) {
+	for _, tag := range tags {
+		_ = f.updateTagIndex(tag, func(keys []string) []string {
+			if f.stillTagged(key, tag) {
+				return keys
+			}
+			return slices.DeleteFunc(keys, func(k string) bool { return k == key })
+		})
+	}
+}
+
+// stillTagged 判断键当前的缓存文件是否未过期且关联了tag
+func (f *FileCache) stillTagged(key, tag string) bool {
+	_, data, err := readItemFile(f.getFilePath(key))
+	if err != nil {
+		return false
+	}
+	item, err := decodeFileItem(data)
+	return err == nil && item.hasTag(tag) && (item.Expiration.IsZero() || !f.clock.Now().After(item.Expiration))
+}
+
+// readTagIndex 读取标签索引，索引不存在时返回空索引
+func (f *FileCache) readTagIndex(tagPath string) (*fileTagIndex, error) {
+	index := &fileTagIndex{}
+	data, err := os.ReadFile(tagPath)
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorrupted, err)
+	}
+	return index, nil
+}
+
+// getTagPath 获取标签索引文件的路径
+func (f *FileCache) getTagPath(tag string) string {
+	hash := md5.Sum([]byte(tag))
+	return filepath.Join(f.dir, fileTagDir, hex.EncodeToString(hash[:])+".json")
+}
@@ -0,0 +1,269 @@
+package go_cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileFormat 文件缓存写入缓存文件时使用的格式，读取时自动识别两种格式
+type FileFormat string
+
+const (
+	// FileFormatJSON 旧版的JSON格式，为默认值，便于与旧版本的程序共享缓存目录
+	FileFormatJSON FileFormat = "json"
+	// FileFormatBinary 带版本号和CRC32C校验和的二进制格式，值按原始字节保存
+	FileFormatBinary FileFormat = "binary"
+)
+
+// 二进制格式的布局，所有整数均为小端序：
+//
+//	[0:4]   魔数 "\x89GCF"
+//	[4]     版本号，当前为1
+//	[5:13]  过期时间的UnixNano，0表示永不过期
+//	[13:21] 滑动过期时长的纳秒数
+//	[21:25] 键的长度
+//	[25:29] 值的长度
+//	[29:33] 标签编码后的长度
+//
+// 之后依次是键、值和标签，每个标签以4字节长度开头；最后4字节是之前所有字节的CRC32C
+const (
+	fileMagic         = "\x89GCF"
+	fileFormatVersion = 1
+	fileHeaderSize    = 33
+	fileChecksumSize  = 4
+)
+
+// crc32c CRC32C（Castagnoli）校验表
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// 缓存文件的扩展名：JSON格式为.json，二进制格式为.gcf。文件内容的格式由魔数识别，与扩展名无关，
+// 读取时两种扩展名都会查找，因此之前的版本以.json命名的二进制文件仍然可以读取
+const (
+	fileExtJSON   = ".json"
+	fileExtBinary = ".gcf"
+)
+
+// fileExt 返回format格式的缓存文件的扩展名
+func fileExt(format FileFormat) string {
+	if format == FileFormatBinary {
+		return fileExtBinary
+	}
+	return fileExtJSON
+}
+
+// isItemFile 判断文件名是否为缓存文件
+func isItemFile(name string) bool {
+	return strings.HasSuffix(name, fileExtJSON) || strings.HasSuffix(name, fileExtBinary)
+}
+
+// otherItemPath 返回同一个键另一种扩展名的缓存文件路径
+func otherItemPath(path string) string {
+	if base, ok := strings.CutSuffix(path, fileExtBinary); ok {
+		return base + fileExtJSON
+	}
+	return strings.TrimSuffix(path, fileExtJSON) + fileExtBinary
+}
+
+// readItemFile 读取键的缓存文件，path不存在时再读取另一种扩展名的文件，返回实际读取的路径
+func readItemFile(path string) (string, []byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		other := otherItemPath(path)
+		if data, err := os.ReadFile(other); !os.IsNotExist(err) {
+			return other, data, err
+		}
+	}
+	return path, data, err
+}
+
+// validFileFormat 判断format是否为支持的写入格式
+func validFileFormat(format FileFormat) bool {
+	return format == FileFormatJSON || format == FileFormatBinary
+}
+
+// fileFormatOf 根据开头的魔数判断缓存文件的格式
+func fileFormatOf(data []byte) FileFormat {
+	if bytes.HasPrefix(data, []byte(fileMagic)) {
+		return FileFormatBinary
+	}
+	return FileFormatJSON
+}
+
+// encodeFileItem 按format编码缓存项
+func encodeFileItem(item *fileItem, format FileFormat) ([]byte, error) {
+	if format != FileFormatBinary {
+		return json.Marshal(item)
+	}
+
+	value := item.value()
+	tagsLen := tagsSize(item.Tags)
+	n := fileHeaderSize + len(item.Key) + len(value) + tagsLen
+	b := make([]byte, n+fileChecksumSize)
+	copy(b, fileMagic)
+	b[4] = fileFormatVersion
+	binary.LittleEndian.PutUint64(b[5:], uint64(unixNano(item.Expiration)))
+	binary.LittleEndian.PutUint64(b[13:], uint64(item.Sliding))
+	binary.LittleEndian.PutUint32(b[21:], uint32(len(item.Key)))
+	binary.LittleEndian.PutUint32(b[25:], uint32(len(value)))
+	binary.LittleEndian.PutUint32(b[29:], uint32(tagsLen))
+
+	pos := fileHeaderSize
+	pos += copy(b[pos:], item.Key)
+	pos += copy(b[pos:], value)
+	putTags(b[pos:], item.Tags)
+	binary.LittleEndian.PutUint32(b[n:], crc32.Checksum(b[:n], crc32c))
+	return b, nil
+}
+
+// decodeFileItem 解析缓存文件，自动识别JSON和二进制格式；无法解析或校验和不匹配时返回ErrCorrupted
+func decodeFileItem(data []byte) (*fileItem, error) {
+	if fileFormatOf(data) == FileFormatJSON {
+		var item fileItem
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorrupted, err)
+		}
+		return &item, nil
+	}
+
+	if len(data) < fileHeaderSize+fileChecksumSize {
+		return nil, fmt.Errorf("%w: truncated file of %d bytes", ErrCorrupted, len(data))
+	}
+	if version := data[4]; version != fileFormatVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupportedFormat, version)
+	}
+	keyLen := int(binary.LittleEndian.Uint32(data[21:]))
+	valueLen := int(binary.LittleEndian.Uint32(data[25:]))
+	tagsLen := int(binary.LittleEndian.Uint32(data[29:]))
+	n := fileHeaderSize + keyLen + valueLen + tagsLen
+	if keyLen < 0 || valueLen < 0 || tagsLen < 0 || n < 0 || len(data) != n+fileChecksumSize {
+		return nil, fmt.Errorf("%w: length mismatch", ErrCorrupted)
+	}
+	if crc32.Checksum(data[:n], crc32c) != binary.LittleEndian.Uint32(data[n:]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCorrupted)
+	}
+
+	pos := fileHeaderSize
+	key := string(data[pos : pos+keyLen])
+	pos += keyLen
+	item := newFileItem(key, string(data[pos:pos+valueLen]), fromUnixNano(int64(binary.LittleEndian.Uint64(data[5:]))))
+	item.Sliding = time.Duration(binary.LittleEndian.Uint64(data[13:]))
+	pos += valueLen
+	tags, err := parseTags(data[pos : pos+tagsLen])
+	if err != nil {
+		return nil, err
+	}
+	item.Tags = tags
+	return item, nil
+}
+
+// tagsSize 返回标签编码后的字节数
+func tagsSize(tags []string) int {
+	n := 0
+	for _, tag := range tags {
+		n += 4 + len(tag)
+	}
+	return n
+}
+
+// putTags 将标签编码到b中，每个标签以4字节长度开头
+func putTags(b []byte, tags []string) {
+	pos := 0
+	for _, tag := range tags {
+		binary.LittleEndian.PutUint32(b[pos:], uint32(len(tag)))
+		pos += 4
+		pos += copy(b[pos:], tag)
+	}
+}
+
+// parseTags 解析putTags编码的标签
+func parseTags(data []byte) ([]string, error) {
+	var tags []string
+	for pos := 0; pos < len(data); {
+		if len(data)-pos < 4 {
+			return nil, fmt.Errorf("%w: malformed tags", ErrCorrupted)
+		}
+		tagLen := int(binary.LittleEndian.Uint32(data[pos:]))
+		pos += 4
+		if tagLen < 0 || tagLen > len(data)-pos {
+			return nil, fmt.Errorf("%w: malformed tags", ErrCorrupted)
+		}
+		tags = append(tags, string(data[pos:pos+tagLen]))
+		pos += tagLen
+	}
+	return tags, nil
+}
+
+// Migrate 将缓存目录中不是当前写入格式或扩展名不符的缓存文件改写为当前格式和扩展名，返回改写的文件数。
+// 每个文件在键锁内改写，可以在其他进程读写缓存时运行；已过期、已损坏的文件和旧版本写入的没有键的文件不改写
+func (f *FileCache) Migrate() (int, error) {
+	migrated := 0
+	err := filepath.WalkDir(f.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != f.dir && !isHashDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isItemFile(d.Name()) {
+			return nil
+		}
+		ok, err := f.migrateFile(path)
+		if ok {
+			migrated++
+		}
+		return err
+	})
+	return migrated, err
+}
+
+// migrateFile 在键锁内改写一个缓存文件，返回是否改写
+func (f *FileCache) migrateFile(path string) (bool, error) {
+	item, ok := f.migrationCandidate(path)
+	if !ok || item.Key == "" {
+		return false, nil
+	}
+	unlock, err := f.lockKey(item.Key)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	// 加锁前文件可能已被改写，重新读取
+	item, ok = f.migrationCandidate(path)
+	if !ok {
+		return false, nil
+	}
+	target := f.getFilePath(item.Key)
+	if target != path {
+		// 当前扩展名的文件已经存在时，path是改写后未能删除的旧文件
+		if _, err := os.Stat(target); err == nil {
+			return false, f.removeFile(path)
+		}
+	}
+	// writeItem写入当前扩展名的文件并删除另一种扩展名的旧文件
+	return true, f.writeItem(target, item)
+}
+
+// migrationCandidate 读取不是当前写入格式或扩展名不符、未过期且可以解析的缓存文件
+func (f *FileCache) migrationCandidate(path string) (*fileItem, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil || (fileFormatOf(data) == f.format && filepath.Ext(path) == fileExt(f.format)) {
+		return nil, false
+	}
+	item, err := decodeFileItem(data)
+	if err != nil || (!item.Expiration.IsZero() && f.clock.Now().After(item.Expiration)) {
+		return nil, false
+	}
+	return item, true
+}
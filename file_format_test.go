@@ -0,0 +1,211 @@
+package go_cache
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newBinaryFileTestCache 在临时目录中创建使用FileFormatBinary的文件缓存
+func newBinaryFileTestCache(t *testing.T, clock Clock) *FileCache {
+	cache, err := NewFileCacheWithOptions(t.TempDir(), FileCacheOptions{Format: FileFormatBinary, Clock: clock})
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	return cache
+}
+
+func TestFileCache_BinaryFormat(t *testing.T) {
+	dir := t.TempDir()
+	binaryCache, err := NewFileCacheWithOptions(dir, FileCacheOptions{Format: FileFormatBinary})
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	jsonCache, _ := NewFileCache(dir)
+
+	payload := string([]byte{0xff, 0x00, 0xfe, 0x01})
+	_ = binaryCache.SetWithTags("bin", payload, time.Hour, "a", "b")
+	_ = binaryCache.SetSliding("sliding", "v", time.Hour)
+	_ = jsonCache.Set("json", "legacy", 0)
+
+	data, _ := os.ReadFile(binaryCache.getFilePath("bin"))
+	if !bytes.HasPrefix(data, []byte(fileMagic)) {
+		t.Errorf("期望以魔数开头, 实际 %q", data[:4])
+	}
+	if want := fileHeaderSize + len("bin") + len(payload) + 2*(4+1) + fileChecksumSize; len(data) != want {
+		t.Errorf("期望文件 %d 字节, 实际 %d", want, len(data))
+	}
+
+	// 两种格式可以互相读取
+	for name, cache := range map[string]*FileCache{"binary": binaryCache, "json": jsonCache} {
+		if got, _ := cache.Get("bin"); got != payload {
+			t.Errorf("%s: 期望读取二进制格式的值, 实际 %q", name, got)
+		}
+		if got, _ := cache.Get("json"); got != "legacy" {
+			t.Errorf("%s: 期望读取JSON格式的值, 实际 %q", name, got)
+		}
+		if keys, _ := cache.TagKeys("b"); len(keys) != 1 || keys[0] != "bin" {
+			t.Errorf("%s: 期望标签索引 [bin], 实际 %v", name, keys)
+		}
+		if ttl, _ := cache.TTL("bin"); ttl <= 0 || ttl > time.Hour {
+			t.Errorf("%s: 期望保留过期时间, 实际 %v", name, ttl)
+		}
+	}
+	item, _ := jsonCache.readItem(jsonCache.getFilePath("sliding"), nil)
+	if item.Sliding != time.Hour || len(item.Tags) != 0 {
+		t.Errorf("期望保留滑动过期时长, 实际 %+v", item)
+	}
+
+	if _, err := NewFileCacheWithOptions(dir, FileCacheOptions{Format: "xml"}); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("期望未知格式返回ErrInvalidParameter, 实际 %v", err)
+	}
+}
+
+func TestFileCache_BinaryChecksum(t *testing.T) {
+	cache := newBinaryFileTestCache(t, nil)
+	_ = cache.Set("k", "value", 0)
+	filePath := cache.getFilePath("k")
+	data, _ := os.ReadFile(filePath)
+
+	// 值中的任意一个字节被改动都能发现
+	corrupted := bytes.Clone(data)
+	corrupted[fileHeaderSize+2] ^= 0x01
+	_ = os.WriteFile(filePath, corrupted, 0644)
+	if _, err := cache.Get("k"); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("期望校验和不匹配时返回ErrCorrupted, 实际 %v", err)
+	}
+	_ = os.WriteFile(filePath, data[:len(data)-1], 0644)
+	if _, err := cache.Get("k"); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("期望截断的文件返回ErrCorrupted, 实际 %v", err)
+	}
+
+	// 更新版本写入的文件不当作损坏删除
+	newer := bytes.Clone(data)
+	newer[4] = fileFormatVersion + 1
+	_ = os.WriteFile(filePath, newer, 0644)
+	if _, err := cache.Get("k"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("期望返回ErrUnsupportedFormat, 实际 %v", err)
+	}
+	_, _ = cache.Exists("k")
+	if _, err := os.Stat(filePath); err != nil {
+		t.Errorf("期望不支持的版本不被删除, 实际 %v", err)
+	}
+	if keys, err := cache.Keys("*"); err != nil || len(keys) != 0 {
+		t.Errorf("期望枚举时跳过不支持的版本, 实际 %v %v", keys, err)
+	}
+}
+
+func TestFileCache_Migrate(t *testing.T) {
+	dir := t.TempDir()
+	clock := NewFakeClock(time.Now())
+	jsonCache, _ := NewFileCacheWithOptions(dir, FileCacheOptions{Clock: clock})
+	_ = jsonCache.SetWithTags("a", "1", 0, "tag")
+	_ = jsonCache.Set("b", strings.Repeat("x", 100), time.Hour)
+	_ = jsonCache.Set("expired", "v", time.Second)
+	// 旧版本写入的文件没有键
+	legacy := jsonCache.getFilePath("legacy")
+	_ = os.MkdirAll(filepath.Dir(legacy), 0755)
+	_ = os.WriteFile(legacy, []byte(`{"value":"old","expiration":"0001-01-01T00:00:00Z"}`), 0644)
+	clock.Advance(time.Minute)
+
+	binaryCache, _ := NewFileCacheWithOptions(dir, FileCacheOptions{Format: FileFormatBinary, Clock: clock})
+	n, err := binaryCache.Migrate()
+	if err != nil || n != 2 {
+		t.Fatalf("期望改写2个文件, 实际 %d %v", n, err)
+	}
+	for _, key := range []string{"a", "b"} {
+		data, _ := os.ReadFile(binaryCache.getFilePath(key))
+		if fileFormatOf(data) != FileFormatBinary {
+			t.Errorf("期望 %s 已改写为二进制格式", key)
+		}
+		if _, err := os.Stat(jsonCache.getFilePath(key)); !os.IsNotExist(err) {
+			t.Errorf("期望 %s 的.json文件已删除, 实际 %v", key, err)
+		}
+	}
+	if got, _ := binaryCache.Get("b"); got != strings.Repeat("x", 100) {
+		t.Errorf("期望迁移后值不变, 实际 %q", got)
+	}
+	if ttl, _ := binaryCache.TTL("b"); ttl != time.Hour-time.Minute {
+		t.Errorf("期望迁移后过期时间不变, 实际 %v", ttl)
+	}
+	if got, _ := binaryCache.Get("legacy"); got != "old" {
+		t.Errorf("期望没有键的旧文件仍然可读, 实际 %q", got)
+	}
+
+	// 再次迁移没有需要改写的文件；也可以迁移回JSON
+	if n, _ := binaryCache.Migrate(); n != 0 {
+		t.Errorf("期望再次迁移时不改写, 实际 %d", n)
+	}
+	if n, _ := jsonCache.Migrate(); n != 2 {
+		t.Errorf("期望迁移回JSON时改写2个文件, 实际 %d", n)
+	}
+}
+
+func TestFileCache_FileExtension(t *testing.T) {
+	dir := t.TempDir()
+	binaryCache, _ := NewFileCacheWithOptions(dir, FileCacheOptions{Format: FileFormatBinary})
+	jsonCache, _ := NewFileCache(dir)
+	if !strings.HasSuffix(binaryCache.getFilePath("k"), ".gcf") || !strings.HasSuffix(jsonCache.getFilePath("k"), ".json") {
+		t.Fatalf("期望二进制文件以.gcf结尾、JSON文件以.json结尾, 实际 %s %s", binaryCache.getFilePath("k"), jsonCache.getFilePath("k"))
+	}
+
+	// 之前的版本以.json命名的二进制文件仍然可以读取和枚举
+	_ = binaryCache.Set("k", "old", 0)
+	_ = os.Rename(binaryCache.getFilePath("k"), jsonCache.getFilePath("k"))
+	if got, _ := binaryCache.Get("k"); got != "old" {
+		t.Errorf("期望读取.json命名的二进制文件, 实际 %q", got)
+	}
+	if keys, _ := binaryCache.Keys("*"); len(keys) != 1 {
+		t.Errorf("期望枚举到1个键, 实际 %v", keys)
+	}
+
+	// Migrate把它重命名为.gcf
+	if n, err := binaryCache.Migrate(); n != 1 || err != nil {
+		t.Fatalf("期望改写1个文件, 实际 %d %v", n, err)
+	}
+	if _, err := os.Stat(jsonCache.getFilePath("k")); !os.IsNotExist(err) {
+		t.Errorf("期望.json文件被重命名, 实际 %v", err)
+	}
+	if got, _ := binaryCache.Get("k"); got != "old" {
+		t.Errorf("期望重命名后值不变, 实际 %q", got)
+	}
+
+	// 写入后删除另一种扩展名的文件，两种格式的缓存交替写入同一个键只留下一个文件
+	_ = jsonCache.Set("k", "json", 0)
+	if _, err := os.Stat(binaryCache.getFilePath("k")); !os.IsNotExist(err) {
+		t.Errorf("期望JSON格式写入后删除.gcf文件, 实际 %v", err)
+	}
+	if got, _ := binaryCache.Get("k"); got != "json" {
+		t.Errorf("期望读取JSON格式写入的值, 实际 %q", got)
+	}
+
+	// 写入新文件后、删除旧文件前崩溃时两个文件同时存在，以当前扩展名的文件为准
+	_ = binaryCache.Set("k", "new", 0)
+	_ = os.WriteFile(jsonCache.getFilePath("k"), []byte(`{"key":"k","value":"stale"}`), 0644)
+	if got, _ := binaryCache.Get("k"); got != "new" {
+		t.Errorf("期望读取.gcf文件, 实际 %q", got)
+	}
+	if keys, _ := binaryCache.Keys("*"); len(keys) != 1 {
+		t.Errorf("期望同一个键只枚举一次, 实际 %v", keys)
+	}
+	if err := binaryCache.Sweep(); err != nil {
+		t.Fatalf("清理失败: %v", err)
+	}
+	if _, err := os.Stat(jsonCache.getFilePath("k")); !os.IsNotExist(err) {
+		t.Errorf("期望Sweep删除被取代的.json文件, 实际 %v", err)
+	}
+	if got, _ := binaryCache.Get("k"); got != "new" {
+		t.Errorf("期望清理后值不变, 实际 %q", got)
+	}
+
+	// Delete删除两种扩展名的文件
+	_ = os.WriteFile(jsonCache.getFilePath("k"), []byte(`{"key":"k","value":"stale"}`), 0644)
+	_ = binaryCache.Delete("k")
+	if exists, _ := binaryCache.Exists("k"); exists {
+		t.Error("期望删除后键不存在")
+	}
+}
@@ -0,0 +1,268 @@
+package go_cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fileTempMaxAge 超过该时长的临时文件视为写入时崩溃残留，由Sweep删除
+const fileTempMaxAge = time.Hour
+
+// fileEntry Sweep遍历到的一个缓存文件
+type fileEntry struct {
+	path    string
+	key     string
+	size    int64
+	modTime time.Time // 设置了容量限制时为最近一次写入或Get命中的时间
+}
+
+// Sweep 遍历两级哈希目录：删除已过期的缓存文件、写入时崩溃残留的临时文件和空的子目录，
+// 再按MaxBytes和MaxFiles淘汰最久未使用的文件，最后从标签索引中删除已不存在或不再关联该标签的键。
+// 后台清理定期调用，也可以手动调用
+func (f *FileCache) Sweep() error {
+	ev := f.notifier.collect()
+	defer ev.flush()
+
+	var entries []fileEntry
+	var total int64
+	firstLevel, err := os.ReadDir(f.dir)
+	if err != nil {
+		return err
+	}
+	for _, d1 := range firstLevel {
+		if !d1.IsDir() || !isHashDir(d1.Name()) {
+			continue
+		}
+		dir1 := filepath.Join(f.dir, d1.Name())
+		secondLevel, err := os.ReadDir(dir1)
+		if err != nil {
+			return err
+		}
+		for _, d2 := range secondLevel {
+			if !d2.IsDir() || !isHashDir(d2.Name()) {
+				continue
+			}
+			dir2 := filepath.Join(dir1, d2.Name())
+			live, err := f.sweepDir(dir2, ev)
+			if err != nil {
+				return err
+			}
+			if len(live) == 0 {
+				// 目录非空时删除失败，忽略
+				_ = os.Remove(dir2)
+			}
+			for _, e := range live {
+				total += e.size
+			}
+			entries = append(entries, live...)
+		}
+		_ = os.Remove(dir1)
+	}
+
+	if err := f.enforceQuota(entries, total, ev); err != nil {
+		return err
+	}
+	return f.sweepTagIndexes()
+}
+
+// sweepTagIndexes 清理所有标签索引中的过期、已删除和已改为其他标签的键
+func (f *FileCache) sweepTagIndexes() error {
+	files, err := os.ReadDir(filepath.Join(f.dir, fileTagDir))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		index, err := f.readTagIndex(filepath.Join(f.dir, fileTagDir, file.Name()))
+		if err != nil || index.Tag == "" {
+			// 之前的版本写入的索引没有标签名，下次更新该标签时补上
+			continue
+		}
+		if err := f.updateTagIndex(index.Tag, func(keys []string) []string {
+			return slices.DeleteFunc(keys, func(key string) bool {
+				return !f.stillTagged(key, index.Tag)
+			})
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sweepDir 处理一个二级目录，返回其中未过期的缓存文件
+func (f *FileCache) sweepDir(dir string, ev *evictEvents) ([]fileEntry, error) {
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(files))
+	for _, file := range files {
+		names[file.Name()] = true
+	}
+	now := f.clock.Now()
+	var live []fileEntry
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		if strings.Contains(file.Name(), ".tmp-") {
+			// 文件的修改时间是真实时间，不与注入的时钟比较
+			if time.Since(info.ModTime()) > fileTempMaxAge {
+				_ = os.Remove(path)
+			}
+			continue
+		}
+		if !isItemFile(file.Name()) {
+			continue
+		}
+		if f.supersededItemFile(file.Name(), names) {
+			// 读取时以当前扩展名的文件为准，已被取代的旧文件在键锁内删除
+			if err := f.removeSuperseded(path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		item, err := f.peekItem(path)
+		if err != nil {
+			continue
+		}
+		if !item.Expiration.IsZero() && now.After(item.Expiration) {
+			if err := f.removeExpired(item.Key, path, ev); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		live = append(live, fileEntry{path: path, key: item.Key, size: info.Size(), modTime: info.ModTime()})
+	}
+	return live, nil
+}
+
+// peekItem 读取缓存文件，不检查是否过期
+func (f *FileCache) peekItem(path string) (*fileItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeFileItem(data)
+}
+
+// removeExpired 在键锁内确认文件仍然过期后删除，避免删除并发写入的新值；旧版本写入的文件没有键，直接删除
+func (f *FileCache) removeExpired(key, path string, ev *evictEvents) error {
+	if key == "" {
+		return f.removeFile(path)
+	}
+	unlock, err := f.lockKey(key)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// readItem删除过期的文件并记录事件
+	_, err = f.readItem(path, ev)
+	if errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrCorrupted) {
+		return nil
+	}
+	return err
+}
+
+// removeCorrupted 在键锁内确认文件仍然损坏后删除
+func (f *FileCache) removeCorrupted(key, path string) error {
+	unlock, err := f.lockKey(key)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := f.readItem(path, nil); !errors.Is(err, ErrCorrupted) {
+		return nil
+	}
+	return f.removeItemFiles(path)
+}
+
+// removeSuperseded 在键锁内确认当前扩展名的文件仍然存在后，删除已被其取代的旧文件path。
+// 写入方在键锁内写入新文件并删除旧文件，因此持有键锁时两个文件同时存在说明写入方在两步之间崩溃
+func (f *FileCache) removeSuperseded(path string) error {
+	current := otherItemPath(path)
+	item, err := f.peekItem(current)
+	if err != nil || item.Key == "" {
+		return nil
+	}
+	unlock, err := f.lockKey(item.Key)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := os.Stat(current); err != nil {
+		return nil
+	}
+	return f.removeFile(path)
+}
+
+// enforceQuota 超出MaxBytes或MaxFiles时按最近使用时间从旧到新淘汰文件
+func (f *FileCache) enforceQuota(entries []fileEntry, total int64, ev *evictEvents) error {
+	over := func() bool {
+		return (f.maxBytes > 0 && total > f.maxBytes) || (f.maxFiles > 0 && len(entries) > f.maxFiles)
+	}
+	if !over() {
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+	for over() {
+		e := entries[0]
+		entries = entries[1:]
+		total -= e.size
+		if err := f.evictFile(e, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evictFile 因容量限制删除缓存文件
+func (f *FileCache) evictFile(e fileEntry, ev *evictEvents) error {
+	if e.key == "" {
+		return f.removeFile(e.path)
+	}
+	unlock, err := f.lockKey(e.key)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f.noteRemoval(e.key, e.path, EvictReasonEvicted, ev)
+	return f.removeItemFiles(e.path)
+}
+
+// markAccess 设置了容量限制时，将文件的修改时间更新为当前的真实时间，作为LRU淘汰的依据。
+// 写入文件时操作系统记录的也是真实时间，注入的时钟只用于判断是否过期
+func (f *FileCache) markAccess(filePath string) {
+	if f.maxBytes <= 0 && f.maxFiles <= 0 {
+		return
+	}
+	now := time.Now()
+	_ = os.Chtimes(filePath, now, now)
+}
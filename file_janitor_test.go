@@ -0,0 +1,165 @@
+package go_cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestFileCache_Janitor(t *testing.T) {
+	defer Init()()
+	clock := NewFakeClock(time.Now())
+	cache, err := NewFileCacheWithOptions(testFilePath, FileCacheOptions{Clock: clock, JanitorInterval: time.Minute})
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	defer cache.Close()
+	recorder := &evictRecorder{}
+	cache.OnEvict(recorder.record)
+
+	_ = cache.Set("a", "1", time.Second)
+	_ = cache.Set("b", "2", 0)
+	_ = cache.Set("c", "3", time.Second)
+	// 写入时崩溃残留的临时文件，超过1小时的会被删除
+	stale := cache.getFilePath("b") + ".tmp-1"
+	fresh := cache.getFilePath("b") + ".tmp-2"
+	_ = os.WriteFile(stale, []byte("{"), 0644)
+	_ = os.WriteFile(fresh, []byte("{"), 0644)
+	_ = os.Chtimes(stale, time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour))
+
+	clock.Advance(time.Minute)
+
+	got := recorder.take()
+	sort.Strings(got)
+	if fmt.Sprint(got) != "[expired:a=1 expired:c=3]" {
+		t.Errorf("期望后台清理删除过期文件, 实际事件 %v", got)
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, err := os.Stat(cache.getFilePath(key)); !os.IsNotExist(err) {
+			t.Errorf("期望 %s 的文件已删除, 实际 %v", key, err)
+		}
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("期望残留的旧临时文件被删除")
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("期望新的临时文件保留, 实际 %v", err)
+	}
+	// 只剩b所在的两级子目录
+	dirs, _ := filepath.Glob(filepath.Join(testFilePath, "[0-9a-f][0-9a-f]", "*"))
+	if len(dirs) != 1 || dirs[0] != filepath.Dir(cache.getFilePath("b")) {
+		t.Errorf("期望空的子目录被删除, 实际 %v", dirs)
+	}
+	if got, _ := cache.Get("b"); got != "2" {
+		t.Errorf("期望未过期的键保留, 实际 %q", got)
+	}
+
+	// 子目录被删除后仍然可以写入
+	if err := cache.Set("a", "4", 0); err != nil {
+		t.Errorf("写入失败: %v", err)
+	}
+}
+
+func TestFileCache_Quota(t *testing.T) {
+	defer Init()()
+	clock := NewFakeClock(time.Now())
+	cache, err := NewFileCacheWithOptions(testFilePath, FileCacheOptions{Clock: clock, MaxFiles: 3})
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	recorder := &evictRecorder{}
+	cache.OnEvict(recorder.record)
+
+	for i := 1; i <= 5; i++ {
+		_ = cache.Set(fmt.Sprintf("k%d", i), i, 0)
+	}
+	// Get命中把修改时间更新为真实时间，与注入的时钟无关；k2和k4最久未使用
+	clock.Advance(-time.Hour)
+	for _, key := range []string{"k1", "k3", "k5"} {
+		_, _ = cache.Get(key)
+	}
+	if err := cache.Sweep(); err != nil {
+		t.Fatalf("清理失败: %v", err)
+	}
+	if got := recorder.take(); fmt.Sprint(got) != "[evicted:k2=2 evicted:k4=4]" {
+		t.Errorf("期望按LRU淘汰k2和k4, 实际 %v", got)
+	}
+
+	// 按字节数限制只保留最近使用的k3和k5
+	var size int64
+	for _, key := range []string{"k3", "k5"} {
+		info, _ := os.Stat(cache.getFilePath(key))
+		size += info.Size()
+	}
+	cache.maxFiles, cache.maxBytes = 0, size
+	if err := cache.Sweep(); err != nil {
+		t.Fatalf("清理失败: %v", err)
+	}
+	keys, _ := cache.Keys("*")
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != "[k3 k5]" {
+		t.Errorf("期望按字节数淘汰后剩下 [k3 k5], 实际 %v", keys)
+	}
+}
+
+// readSignalClock 测试等待时，Now被调用即通知read
+type readSignalClock struct {
+	*FakeClock
+	read chan struct{}
+}
+
+func (c *readSignalClock) Now() time.Time {
+	select {
+	case c.read <- struct{}{}:
+	default:
+	}
+	return c.FakeClock.Now()
+}
+
+func TestFileCache_ReadPathRemovesUnderLock(t *testing.T) {
+	defer Init()()
+	clock := &readSignalClock{FakeClock: NewFakeClock(time.Now()), read: make(chan struct{})}
+	cache, err := NewFileCacheWithOptions(testFilePath, FileCacheOptions{Clock: clock})
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	_ = cache.Set("k", "old", time.Second)
+	clock.Advance(2 * time.Second)
+
+	// 读取方看到过期的文件时，另一个写入方正持有键锁写入新值
+	unlock, err := cache.lockKey("k")
+	if err != nil {
+		t.Fatalf("加锁失败: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = cache.Get("k")
+		_, _ = cache.Exists("k")
+		_, _ = cache.TTL("k")
+	}()
+	// 读取方已经读到文件并判断其过期，接下来需要键锁才能删除
+	<-clock.read
+	if _, err := os.Stat(cache.getFilePath("k")); err != nil {
+		t.Errorf("期望读取方等待键锁后才删除过期文件, 实际 %v", err)
+	}
+	_ = cache.writeItem(cache.getFilePath("k"), newFileItem("k", "new", time.Time{}))
+	unlock()
+	<-done
+
+	if got, err := cache.Get("k"); got != "new" {
+		t.Errorf("期望读取路径不删除并发写入的新值, 实际 %q %v", got, err)
+	}
+
+	// 损坏的文件同样在键锁内确认后删除
+	_ = os.WriteFile(cache.getFilePath("k"), []byte("{"), 0644)
+	if exists, err := cache.Exists("k"); exists || err != nil {
+		t.Errorf("期望损坏的文件不存在, 实际 %v %v", exists, err)
+	}
+	if _, err := os.Stat(cache.getFilePath("k")); !os.IsNotExist(err) {
+		t.Errorf("期望损坏的文件被删除, 实际 %v", err)
+	}
+}
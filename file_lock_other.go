@@ -0,0 +1,20 @@
+//go:build !unix || aix || solaris
+
+package go_cache
+
+import "os"
+
+// lockFile 当前平台不支持flock（Windows、AIX、Solaris等），只依赖进程内锁
+func lockFile(f *os.File) error {
+	return nil
+}
+
+// tryLockFile 当前平台不支持flock，总是成功
+func tryLockFile(f *os.File) (bool, error) {
+	return true, nil
+}
+
+// unlockFile 当前平台不支持flock
+func unlockFile(f *os.File) error {
+	return nil
+}
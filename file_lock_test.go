@@ -0,0 +1,109 @@
+package go_cache
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fileLockHelperEnv 设置该环境变量时，TestFileCache_LockHelperProcess作为子进程操作共享的缓存目录
+const fileLockHelperEnv = "GO_CACHE_FILE_LOCK_HELPER"
+
+// fileLockHelperOps 每个子进程执行的读-改-写次数
+const fileLockHelperOps = 100
+
+func TestFileCache_CrossProcessLock(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "aix" || runtime.GOOS == "solaris" || runtime.GOOS == "illumos" {
+		t.Skip("当前平台不支持flock")
+	}
+	defer Init()()
+	cache, err := NewFileCache(testFilePath)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+
+	const processes = 4
+	cmds := make([]*exec.Cmd, processes)
+	outputs := make([]*strings.Builder, processes)
+	for i := range cmds {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestFileCache_LockHelperProcess$")
+		cmd.Env = append(os.Environ(), fileLockHelperEnv+"="+testFilePath)
+		outputs[i] = &strings.Builder{}
+		cmd.Stdout = outputs[i]
+		cmd.Stderr = outputs[i]
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("启动子进程失败: %v", err)
+		}
+		cmds[i] = cmd
+	}
+	winners := 0
+	for i, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("子进程失败: %v\n%s", err, outputs[i])
+		}
+		if strings.Contains(outputs[i].String(), "setnx:true") {
+			winners++
+		}
+	}
+
+	if got, _ := cache.Get("counter"); got != strconv.Itoa(processes*fileLockHelperOps) {
+		t.Errorf("期望计数器为 %d, 实际 %s", processes*fileLockHelperOps, got)
+	}
+	if got, _ := cache.Get("cas"); got != strconv.Itoa(processes*fileLockHelperOps) {
+		t.Errorf("期望CompareAndSwap累加到 %d, 实际 %s", processes*fileLockHelperOps, got)
+	}
+	if winners != 1 {
+		t.Errorf("期望只有1个进程SetNX成功, 实际 %d", winners)
+	}
+	if ttl, _ := cache.TTL("counter"); ttl <= 0 {
+		t.Errorf("期望计数器带有Expire设置的过期时间, 实际TTL %v", ttl)
+	}
+}
+
+// TestFileCache_LockHelperProcess 不是真正的测试，由TestFileCache_CrossProcessLock作为子进程启动
+func TestFileCache_LockHelperProcess(t *testing.T) {
+	dir := os.Getenv(fileLockHelperEnv)
+	if dir == "" {
+		t.Skip("仅作为子进程运行")
+	}
+	cache, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	defer cache.Close()
+
+	won, err := cache.SetNX("winner", os.Getpid(), 0)
+	if err != nil {
+		t.Fatalf("SetNX失败: %v", err)
+	}
+	fmt.Printf("setnx:%v\n", won)
+
+	for i := 0; i < fileLockHelperOps; i++ {
+		if _, err := cache.Incr("counter", 0); err != nil {
+			t.Fatalf("Incr失败: %v", err)
+		}
+		// Expire同样是读-改-写，不能覆盖其他进程的Incr
+		if err := cache.Expire("counter", time.Hour); err != nil {
+			t.Fatalf("Expire失败: %v", err)
+		}
+		// 基于CompareAndSwap的乐观更新，冲突时重试
+		for {
+			old, err := cache.Get("cas")
+			if err == ErrKeyNotFound {
+				if ok, _ := cache.SetNX("cas", "1", 0); ok {
+					break
+				}
+				continue
+			}
+			n, _ := strconv.Atoi(old)
+			if ok, _ := cache.CompareAndSwap("cas", old, strconv.Itoa(n+1), 0); ok {
+				break
+			}
+		}
+	}
+}
@@ -11,6 +11,7 @@ func TestAllCacheImplementations(t *testing.T) {
 	caches := map[string]Cache{
 		"redis":  redisServer,
 		"memory": NewMemoryCache(),
+		"arena":  newArenaTestCache(),
 	}
 
 	// 添加文件缓存（需要特殊处理）
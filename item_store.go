@@ -0,0 +1,97 @@
+package go_cache
+
+import (
+	"time"
+)
+
+// itemStore 保存一个分片中的缓存项，调用方必须持有分片锁
+type itemStore interface {
+	// get 返回键对应的缓存项，返回值只能读取，修改需要通过put或setExpiration写回
+	get(key string) (*cacheItem, bool)
+
+	// put 写入缓存项，返回被覆盖的旧缓存项，以及为腾出空间被移除的其他缓存项
+	put(item *cacheItem) (old *cacheItem, evicted []*cacheItem, err error)
+
+	// remove 删除键并返回被删除的缓存项
+	remove(key string) (*cacheItem, bool)
+
+	// setExpiration 修改已存在的缓存项的过期时间
+	setExpiration(item *cacheItem, expiration time.Time)
+
+	// popExpired 删除最多limit个已过期的缓存项，返回被删除的缓存项以及是否还有已过期的缓存项
+	popExpired(now time.Time, limit int) (removed []*cacheItem, more bool)
+
+	// forEach 遍历所有缓存项，包括已过期但未清理的
+	forEach(fn func(item *cacheItem))
+
+	len() int
+	reset()
+}
+
+// mapStore 使用map保存缓存项，并用最小堆维护过期时间索引
+type mapStore struct {
+	data map[string]*cacheItem
+	ttl  expiryHeap
+}
+
+func newMapStore() *mapStore {
+	return &mapStore{data: make(map[string]*cacheItem)}
+}
+
+func (ms *mapStore) get(key string) (*cacheItem, bool) {
+	item, ok := ms.data[key]
+	return item, ok
+}
+
+func (ms *mapStore) put(item *cacheItem) (*cacheItem, []*cacheItem, error) {
+	old, exists := ms.data[item.key]
+	if exists {
+		ms.ttl.remove(old)
+	}
+	ms.data[item.key] = item
+	ms.ttl.add(item)
+	return old, nil, nil
+}
+
+func (ms *mapStore) remove(key string) (*cacheItem, bool) {
+	item, exists := ms.data[key]
+	if exists {
+		ms.ttl.remove(item)
+		delete(ms.data, key)
+	}
+	return item, exists
+}
+
+func (ms *mapStore) setExpiration(item *cacheItem, expiration time.Time) {
+	ms.ttl.update(item, expiration)
+}
+
+func (ms *mapStore) popExpired(now time.Time, limit int) ([]*cacheItem, bool) {
+	var removed []*cacheItem
+	for {
+		item, ok := ms.ttl.peek()
+		if !ok || !item.expired(now) {
+			return removed, false
+		}
+		if len(removed) == limit {
+			return removed, true
+		}
+		ms.remove(item.key)
+		removed = append(removed, item)
+	}
+}
+
+func (ms *mapStore) forEach(fn func(item *cacheItem)) {
+	for _, item := range ms.data {
+		fn(item)
+	}
+}
+
+func (ms *mapStore) len() int {
+	return len(ms.data)
+}
+
+func (ms *mapStore) reset() {
+	ms.data = make(map[string]*cacheItem)
+	ms.ttl = nil
+}
@@ -3,8 +3,13 @@ package go_cache
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
+	"time"
 )
 
+// ToString 将任意值转换为字符串，无法JSON序列化时回退到%v格式。
+// 缓存写入时使用配置的Codec并返回序列化错误，不再调用ToString
 func ToString(value any) string {
 	switch v := value.(type) {
 	case string:
@@ -22,3 +27,23 @@ func ToString(value any) string {
 		return fmt.Sprintf("%v", v)
 	}
 }
+
+// incrValue 将字符串形式的计数器加上delta，current不是整数或结果溢出时返回ErrNotInteger
+func incrValue(current string, delta int64) (int64, error) {
+	n, err := strconv.ParseInt(current, 10, 64)
+	if err != nil {
+		return 0, ErrNotInteger
+	}
+	if (delta > 0 && n > math.MaxInt64-delta) || (delta < 0 && n < math.MinInt64-delta) {
+		return 0, ErrNotInteger
+	}
+	return n + delta, nil
+}
+
+// expireAt 根据过期时长计算过期时刻，expiration<=0表示永不过期，返回零值
+func expireAt(now time.Time, expiration time.Duration) time.Time {
+	if expiration <= 0 {
+		return time.Time{}
+	}
+	return now.Add(expiration)
+}
@@ -0,0 +1,336 @@
+package go_cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// LoaderFunc 在缓存未命中时加载数据
+type LoaderFunc func(ctx context.Context) (any, error)
+
+// LoadingCache 在任意Cache之上提供加载穿透（GetOrLoad），
+// 同一个键的并发未命中只会调用一次loader，结果由所有等待者共享
+type LoadingCache struct {
+	cache       Cache
+	codec       Codec
+	negativeTTL time.Duration
+	beta        float64
+	clock       Clock
+	random      func() float64 // 返回[0,1)内的随机数
+	group       flightGroup
+
+	mu       sync.Mutex
+	failures map[string]loadFailure // 负缓存：加载失败的键
+}
+
+// LoadingCacheOptions 加载穿透的可选配置
+type LoadingCacheOptions struct {
+	// NegativeTTL loader返回错误时缓存该错误的时长，期间同一个键直接返回该错误，0表示不缓存错误
+	NegativeTTL time.Duration
+
+	// XFetchBeta 大于0时启用概率性提前刷新（XFetch）：越接近过期、loader耗时越长，
+	// 命中时越可能在后台提前刷新。通常取1，值越大越早刷新；0表示不提前刷新
+	XFetchBeta float64
+
+	// Clock 读取当前时间的时钟，用于负缓存、软过期时间和loader耗时，默认为系统时钟
+	Clock Clock
+}
+
+// loadFailure 表示一次被缓存的加载失败
+type loadFailure struct {
+	err        error
+	expiration time.Time
+}
+
+// maxLoadFailures 负缓存超过该数量时清理已过期的记录
+const maxLoadFailures = 1024
+
+// NewLoadingCache 创建一个新的加载穿透缓存
+func NewLoadingCache(cache Cache, opts LoadingCacheOptions) *LoadingCache {
+	return &LoadingCache{
+		cache:       cache,
+		codec:       codecOf(cache),
+		negativeTTL: opts.NegativeTTL,
+		beta:        opts.XFetchBeta,
+		clock:       clockOrSystem(opts.Clock),
+		random:      rand.Float64,
+		failures:    make(map[string]loadFailure),
+	}
+}
+
+// GetOrLoad 从缓存中获取指定键的值，未命中时调用loader加载并以ttl写入缓存。
+// loader不会随单个调用方的ctx取消而取消，以免影响其他等待同一个键的调用方；
+// 调用方的ctx取消时GetOrLoad立即返回ctx.Err()。
+func (l *LoadingCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader LoaderFunc) (string, error) {
+	return l.getOrLoad(ctx, key, 0, ttl, loader)
+}
+
+// GetOrLoadStale 与GetOrLoad相同，但写入的值带有软、硬两个过期时间：
+// 超过softTTL后仍然返回旧值，同时在后台调用一次loader刷新（stale-while-revalidate）；
+// 刷新失败时继续返回旧值，直到hardTTL后条目从底层缓存中过期（stale-if-error）。
+// 软过期时刻以固定长度的头部与值一起保存在底层缓存中，因此适用于所有后端；hardTTL<=0表示底层缓存中永不过期
+func (l *LoadingCache) GetOrLoadStale(ctx context.Context, key string, softTTL, hardTTL time.Duration, loader LoaderFunc) (string, error) {
+	return l.getOrLoad(ctx, key, softTTL, hardTTL, loader)
+}
+
+// getOrLoad 命中时按需在后台刷新，未命中时加载
+func (l *LoadingCache) getOrLoad(ctx context.Context, key string, softTTL, hardTTL time.Duration, loader LoaderFunc) (string, error) {
+	cache := WithContext(l.cache)
+	raw, err := cache.GetCtx(ctx, key)
+	if err == nil {
+		value, meta := decodeEnvelope(raw)
+		if l.needsRefresh(meta) {
+			l.refresh(ctx, key, softTTL, hardTTL, loader)
+		}
+		return value, nil
+	}
+	if !errors.Is(err, ErrKeyNotFound) {
+		return "", err
+	}
+	return l.loadMiss(ctx, key, softTTL, hardTTL, loader)
+}
+
+// needsRefresh 判断命中的值是否需要在后台刷新：已超过软过期时刻，
+// 或按XFetch以 now - delta*beta*ln(rand) >= 过期时刻 的概率提前刷新
+func (l *LoadingCache) needsRefresh(env loadMeta) bool {
+	deadline := env.soft
+	if deadline.IsZero() {
+		deadline = env.expiry
+	}
+	if deadline.IsZero() {
+		return false
+	}
+	now := l.clock.Now()
+	if !now.Before(deadline) {
+		return true
+	}
+	if l.beta <= 0 || env.delta <= 0 {
+		return false
+	}
+	gap := -float64(env.delta) * l.beta * math.Log(1-l.random())
+	return gap >= float64(deadline.Sub(now))
+}
+
+// loadMiss 处理缓存未命中：合并并发调用，调用loader并写入缓存
+func (l *LoadingCache) loadMiss(ctx context.Context, key string, softTTL, hardTTL time.Duration, loader LoaderFunc) (string, error) {
+	if err := l.failure(key); err != nil {
+		return "", err
+	}
+
+	cache := WithContext(l.cache)
+	return l.group.do(ctx, key, func() (string, error) {
+		loadCtx := context.WithoutCancel(ctx)
+		// 等待期间其他调用方可能已经写入
+		if raw, err := cache.GetCtx(loadCtx, key); err == nil {
+			value, _ := decodeEnvelope(raw)
+			return value, nil
+		}
+		return l.load(loadCtx, key, softTTL, hardTTL, loader)
+	})
+}
+
+// refresh 在后台刷新键，同一个键同时只有一次加载；
+// 刷新失败时保留旧值，负缓存有效期内不再刷新
+func (l *LoadingCache) refresh(ctx context.Context, key string, softTTL, hardTTL time.Duration, loader LoaderFunc) {
+	if l.failure(key) != nil {
+		return
+	}
+	loadCtx := context.WithoutCancel(ctx)
+	l.group.start(key, func() (string, error) {
+		return l.load(loadCtx, key, softTTL, hardTTL, loader)
+	})
+}
+
+// load 调用loader并以hardTTL写入缓存，返回不带头部的值。
+// 设置了软过期时间或启用XFetch时，值的前面附带记录软过期时刻、过期时刻和loader耗时的头部
+func (l *LoadingCache) load(ctx context.Context, key string, softTTL, hardTTL time.Duration, loader LoaderFunc) (string, error) {
+	start := l.clock.Now()
+	loaded, err := loader(ctx)
+	if err != nil {
+		l.recordFailure(key, err)
+		return "", err
+	}
+	value, err := encodeValue(l.codec, loaded)
+	if err != nil {
+		return "", err
+	}
+
+	stored := value
+	if softTTL > 0 || l.beta > 0 {
+		now := l.clock.Now()
+		stored = encodeEnvelope(value, loadMeta{
+			soft:   expireAt(now, softTTL),
+			expiry: expireAt(now, hardTTL),
+			delta:  now.Sub(start),
+		})
+	}
+	return value, WithContext(l.cache).SetCtx(ctx, key, stored, hardTTL)
+}
+
+// loadMeta 加载穿透写入缓存的值附带的元数据
+type loadMeta struct {
+	soft   time.Time     // 软过期时刻，零值表示不会软过期
+	expiry time.Time     // 写入时计算的过期时刻，零值表示永不过期
+	delta  time.Duration // loader的耗时
+}
+
+// 附带元数据的值以固定长度的头部开头，整数均为小端序：
+//
+//	[0:4]   loadEnvelopeMagic
+//	[4:12]  软过期时刻的UnixNano，0表示不会软过期
+//	[12:20] 过期时刻的UnixNano，0表示永不过期
+//	[20:28] loader耗时的纳秒数
+//	[28:32] 前28字节的CRC32C
+//
+// 之后是原始值。魔数或校验和不匹配的值原样返回，因此恰好以魔数开头的普通值不会被改写
+const (
+	loadEnvelopeMagic = "\x00GCL"
+	loadEnvelopeSize  = 32
+)
+
+// encodeEnvelope 将元数据编码为头部放在值的前面
+func encodeEnvelope(value string, meta loadMeta) string {
+	b := make([]byte, loadEnvelopeSize, loadEnvelopeSize+len(value))
+	copy(b, loadEnvelopeMagic)
+	binary.LittleEndian.PutUint64(b[4:], uint64(unixNano(meta.soft)))
+	binary.LittleEndian.PutUint64(b[12:], uint64(unixNano(meta.expiry)))
+	binary.LittleEndian.PutUint64(b[20:], uint64(meta.delta))
+	binary.LittleEndian.PutUint32(b[28:], crc32.Checksum(b[:28], crc32c))
+	return string(append(b, value...))
+}
+
+// decodeEnvelope 去掉加载穿透写入的头部，返回原始值和元数据；其他值原样返回，元数据为零值
+func decodeEnvelope(raw string) (string, loadMeta) {
+	if len(raw) < loadEnvelopeSize || raw[:4] != loadEnvelopeMagic {
+		return raw, loadMeta{}
+	}
+	header := []byte(raw[:loadEnvelopeSize])
+	if crc32.Checksum(header[:28], crc32c) != binary.LittleEndian.Uint32(header[28:]) {
+		return raw, loadMeta{}
+	}
+	return raw[loadEnvelopeSize:], loadMeta{
+		soft:   fromUnixNano(int64(binary.LittleEndian.Uint64(header[4:]))),
+		expiry: fromUnixNano(int64(binary.LittleEndian.Uint64(header[12:]))),
+		delta:  time.Duration(binary.LittleEndian.Uint64(header[20:])),
+	}
+}
+
+// fromUnixNano 是unixNano的逆操作，0表示零值
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+// Cache 返回底层缓存
+func (l *LoadingCache) Cache() Cache {
+	return l.cache
+}
+
+// failure 返回仍在负缓存有效期内的加载错误
+func (l *LoadingCache) failure(key string) error {
+	if l.negativeTTL <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, ok := l.failures[key]
+	if !ok {
+		return nil
+	}
+	if l.clock.Now().After(f.expiration) {
+		delete(l.failures, key)
+		return nil
+	}
+	return f.err
+}
+
+// recordFailure 将加载错误写入负缓存
+func (l *LoadingCache) recordFailure(key string, err error) {
+	if l.negativeTTL <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	if len(l.failures) >= maxLoadFailures {
+		for k, f := range l.failures {
+			if now.After(f.expiration) {
+				delete(l.failures, k)
+			}
+		}
+	}
+	l.failures[key] = loadFailure{err: err, expiration: now.Add(l.negativeTTL)}
+}
+
+// flightCall 表示一次正在进行中的加载
+type flightCall struct {
+	done  chan struct{}
+	value string
+	err   error
+}
+
+// flightGroup 合并同一个键的并发调用
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// do 执行fn并与同一个键上的其他调用方共享结果，fn在独立的协程中运行，
+// ctx取消时只有当前调用方提前返回
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (string, error)) (string, error) {
+	g.mu.Lock()
+	call := g.startLocked(key, fn)
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// start 在后台执行fn，不等待结果；同一个键已有进行中的调用时不再启动
+func (g *flightGroup) start(key string, fn func() (string, error)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.startLocked(key, fn)
+}
+
+// startLocked 返回同一个键上进行中的调用，没有时启动fn，调用方必须持有g.mu
+func (g *flightGroup) startLocked(key string, fn func() (string, error)) *flightCall {
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	call, ok := g.calls[key]
+	if !ok {
+		call = &flightCall{done: make(chan struct{})}
+		g.calls[key] = call
+		go g.run(key, call, fn)
+	}
+	return call
+}
+
+func (g *flightGroup) run(key string, call *flightCall, fn func() (string, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("go_cache: loader panic: %v", r)
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	call.value, call.err = fn()
+}
@@ -0,0 +1,383 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadingCache_Coalesce(t *testing.T) {
+	cache := NewMemoryCache()
+	defer cache.Close()
+	lc := NewLoadingCache(cache, LoadingCacheOptions{})
+
+	var calls int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (any, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return map[string]int{"views": 1}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 50)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, err := lc.GetOrLoad(context.Background(), "hot_key", time.Minute, loader)
+			if err != nil {
+				t.Errorf("加载失败: %v", err)
+			}
+			results[i] = value
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("期望loader只调用1次, 实际: %d", calls)
+	}
+	for _, value := range results {
+		if value != `{"views":1}` {
+			t.Errorf("期望值 %s, 实际值 %s", `{"views":1}`, value)
+		}
+	}
+	if got, _ := cache.Get("hot_key"); got != `{"views":1}` {
+		t.Errorf("期望加载结果写入缓存, 实际: %s", got)
+	}
+
+	// 已缓存时不再调用loader
+	_, _ = lc.GetOrLoad(context.Background(), "hot_key", time.Minute, loader)
+	if calls != 1 {
+		t.Errorf("期望命中缓存, loader调用次数: %d", calls)
+	}
+}
+
+func TestLoadingCache_NegativeTTL(t *testing.T) {
+	cache := NewMemoryCache()
+	defer cache.Close()
+	errLoad := errors.New("db down")
+
+	var calls int32
+	loader := func(ctx context.Context) (any, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, errLoad
+	}
+
+	// 不缓存错误
+	lc := NewLoadingCache(cache, LoadingCacheOptions{})
+	for i := 0; i < 2; i++ {
+		if _, err := lc.GetOrLoad(context.Background(), "k", time.Minute, loader); !errors.Is(err, errLoad) {
+			t.Errorf("期望返回loader错误, 实际: %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("期望loader调用2次, 实际: %d", calls)
+	}
+
+	// 缓存错误
+	calls = 0
+	lc = NewLoadingCache(cache, LoadingCacheOptions{NegativeTTL: 100 * time.Millisecond})
+	for i := 0; i < 3; i++ {
+		if _, err := lc.GetOrLoad(context.Background(), "k", time.Minute, loader); !errors.Is(err, errLoad) {
+			t.Errorf("期望返回loader错误, 实际: %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("期望loader调用1次, 实际: %d", calls)
+	}
+	time.Sleep(150 * time.Millisecond)
+	_, _ = lc.GetOrLoad(context.Background(), "k", time.Minute, loader)
+	if calls != 2 {
+		t.Errorf("期望负缓存过期后重新加载, loader调用次数: %d", calls)
+	}
+}
+
+func TestLoadingCache_ContextCanceled(t *testing.T) {
+	cache := NewMemoryCache()
+	defer cache.Close()
+	lc := NewLoadingCache(cache, LoadingCacheOptions{})
+
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (any, error) {
+		<-release
+		return "loaded", nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := lc.GetOrLoad(ctx, "slow", time.Minute, loader); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望返回context.DeadlineExceeded, 实际: %v", err)
+	}
+
+	// 调用方取消不影响正在进行的加载
+	close(release)
+	value, err := lc.GetOrLoad(context.Background(), "slow", time.Minute, loader)
+	if err != nil || value != "loaded" {
+		t.Errorf("期望值 loaded, 实际值 %s %v", value, err)
+	}
+}
+
+func TestLoadingCache_Panic(t *testing.T) {
+	cache := NewMemoryCache()
+	defer cache.Close()
+	lc := NewLoadingCache(cache, LoadingCacheOptions{})
+
+	_, err := lc.GetOrLoad(context.Background(), "panic", time.Minute, func(ctx context.Context) (any, error) {
+		panic("boom")
+	})
+	if err == nil {
+		t.Error("期望loader panic时返回错误")
+	}
+}
+
+func TestLoadingCache_Stale(t *testing.T) {
+	defer Init()()
+	clock := NewFakeClock(time.Now())
+	fileCache, err := NewFileCacheWithOptions(testFilePath, FileCacheOptions{Clock: clock})
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	caches := map[string]Cache{
+		"redis":  redisServer,
+		"memory": NewMemoryCacheWithOptions(MemoryCacheOptions{Clock: clock}),
+		"arena":  NewMemoryCacheWithOptions(MemoryCacheOptions{Storage: ArenaStorage, MaxBytes: 1 << 20, Clock: clock}),
+		"file":   fileCache,
+		"multi":  NewMultiCache(NewMemoryCacheWithOptions(MemoryCacheOptions{Clock: clock}), fileCache),
+	}
+	errLoad := errors.New("api down")
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			defer cache.Delete("stale")
+			lc := NewLoadingCache(cache, LoadingCacheOptions{Clock: clock})
+
+			var calls int32
+			var failing atomic.Bool
+			loader := func(ctx context.Context) (any, error) {
+				n := atomic.AddInt32(&calls, 1)
+				if failing.Load() {
+					return nil, errLoad
+				}
+				return n, nil
+			}
+			get := func() (string, error) {
+				value, err := lc.GetOrLoadStale(context.Background(), "stale", time.Minute, 10*time.Minute, loader)
+				waitForLoad(t, lc, "stale")
+				return value, err
+			}
+
+			if value, err := get(); value != "1" || err != nil {
+				t.Fatalf("期望首次加载返回1, 实际 %q %v", value, err)
+			}
+			if raw, _ := cache.Get("stale"); len(raw) != loadEnvelopeSize+1 {
+				t.Errorf("期望底层缓存中的值带有固定长度的头部, 实际 %q", raw)
+			}
+			if value, _ := get(); value != "1" || atomic.LoadInt32(&calls) != 1 {
+				t.Fatalf("期望软过期前直接命中, 实际 %q, loader调用 %d 次", value, calls)
+			}
+
+			// 软过期后立即返回旧值，后台刷新一次
+			clock.Advance(2 * time.Minute)
+			if value, _ := get(); value != "1" {
+				t.Errorf("期望软过期后返回旧值, 实际 %q", value)
+			}
+			if value, _ := get(); value != "2" {
+				t.Errorf("期望后台刷新后返回新值, 实际 %q", value)
+			}
+
+			// 刷新失败时继续返回旧值
+			failing.Store(true)
+			clock.Advance(2 * time.Minute)
+			for i := 0; i < 2; i++ {
+				if value, err := get(); value != "2" || err != nil {
+					t.Errorf("期望刷新失败时返回旧值, 实际 %q %v", value, err)
+				}
+			}
+			if n := atomic.LoadInt32(&calls); n != 4 {
+				t.Errorf("期望每次软过期的读取触发一次刷新, loader调用 %d 次", n)
+			}
+
+			if name == "redis" {
+				// Redis的硬过期时间由服务端计时，不受FakeClock控制
+				return
+			}
+			clock.Advance(10 * time.Minute)
+			if _, err := get(); !errors.Is(err, errLoad) {
+				t.Errorf("期望硬过期后返回loader错误, 实际 %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadingCache_StaleNegativeTTL(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{Clock: clock})
+	defer cache.Close()
+	lc := NewLoadingCache(cache, LoadingCacheOptions{Clock: clock, NegativeTTL: time.Minute})
+
+	var calls int32
+	loader := func(ctx context.Context) (any, error) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			return nil, errors.New("api down")
+		}
+		return "v", nil
+	}
+	get := func() string {
+		value, _ := lc.GetOrLoadStale(context.Background(), "k", time.Second, time.Hour, loader)
+		waitForLoad(t, lc, "k")
+		return value
+	}
+
+	_ = get()
+	clock.Advance(2 * time.Second)
+	// 负缓存有效期内不再后台刷新
+	for i := 0; i < 3; i++ {
+		if value := get(); value != "v" {
+			t.Errorf("期望返回旧值v, 实际 %q", value)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("期望loader调用2次, 实际 %d", n)
+	}
+
+	// GetOrLoad读取GetOrLoadStale写入的值时去掉头部
+	if value, _ := lc.GetOrLoad(context.Background(), "k", time.Hour, loader); value != "v" {
+		t.Errorf("期望GetOrLoad返回v, 实际 %q", value)
+	}
+	// 元数据与值保存在同一个键中，不会出现额外的键
+	if keys, _ := cache.Keys("*"); fmt.Sprint(keys) != "[k]" {
+		t.Errorf("期望只有用户的键, 实际 %v", keys)
+	}
+
+	// 值被直接覆盖后元数据失效，不再刷新
+	_ = cache.Set("k", "w", time.Hour)
+	clock.Advance(2 * time.Minute)
+	if value := get(); value != "w" || atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("期望返回覆盖后的值且不刷新, 实际 %q, loader调用 %d 次", value, calls)
+	}
+}
+
+// waitForLoad 等待key上进行中的加载（包括后台刷新）结束
+func waitForLoad(t *testing.T, lc *LoadingCache, key string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		lc.group.mu.Lock()
+		_, loading := lc.group.calls[key]
+		lc.group.mu.Unlock()
+		if !loading {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("等待 %s 的加载结束超时", key)
+}
+
+func TestLoadingCache_XFetch(t *testing.T) {
+	defer Init()()
+	clock := NewFakeClock(time.Now())
+	fileCache, err := NewFileCacheWithOptions(testFilePath, FileCacheOptions{Clock: clock})
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	caches := map[string]Cache{
+		"redis":  redisServer,
+		"memory": NewMemoryCacheWithOptions(MemoryCacheOptions{Clock: clock}),
+		"arena":  NewMemoryCacheWithOptions(MemoryCacheOptions{Storage: ArenaStorage, MaxBytes: 1 << 20, Clock: clock}),
+		"file":   fileCache,
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			defer cache.Delete("xfetch")
+			lc := NewLoadingCache(cache, LoadingCacheOptions{Clock: clock, XFetchBeta: 1})
+			// ln(1-r) = -1，即在剩余时间不超过loader耗时*beta时提前刷新
+			lc.random = func() float64 { return 1 - math.Exp(-1) }
+
+			var calls int32
+			loader := func(ctx context.Context) (any, error) {
+				clock.Advance(10 * time.Second) // 模拟loader耗时10秒
+				return atomic.AddInt32(&calls, 1), nil
+			}
+			get := func() string {
+				value, _ := lc.GetOrLoad(context.Background(), "xfetch", time.Minute, loader)
+				waitForLoad(t, lc, "xfetch")
+				return value
+			}
+
+			if value := get(); value != "1" {
+				t.Fatalf("期望首次加载返回1, 实际 %q", value)
+			}
+			raw, _ := cache.Get("xfetch")
+			if value, meta := decodeEnvelope(raw); value != "1" || meta.delta != 10*time.Second {
+				t.Errorf("期望缓存中保存loader耗时10s, 实际 %q %v", value, meta.delta)
+			}
+
+			clock.Advance(45 * time.Second)
+			if value := get(); value != "1" || atomic.LoadInt32(&calls) != 1 {
+				t.Errorf("期望剩余15秒时不提前刷新, 实际 %q, loader调用 %d 次", value, calls)
+			}
+			clock.Advance(6 * time.Second)
+			if value := get(); value != "1" {
+				t.Errorf("期望提前刷新时返回当前值, 实际 %q", value)
+			}
+			if n := atomic.LoadInt32(&calls); n != 2 {
+				t.Errorf("期望剩余9秒时提前刷新, loader调用 %d 次", n)
+			}
+			if value := get(); value != "2" {
+				t.Errorf("期望提前刷新后返回新值, 实际 %q", value)
+			}
+		})
+	}
+}
+
+func TestLoadingCache_XFetchProbability(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{Clock: clock})
+	defer cache.Close()
+	lc := NewLoadingCache(cache, LoadingCacheOptions{Clock: clock, XFetchBeta: 1})
+
+	env := loadMeta{expiry: clock.Now().Add(time.Minute), delta: 10 * time.Second}
+	// 提前刷新的概率为exp(-剩余时间/(delta*beta))，越接近过期概率越大
+	prev := -1.0
+	for _, remaining := range []time.Duration{50 * time.Second, 20 * time.Second, 5 * time.Second} {
+		clock.Advance(env.expiry.Sub(clock.Now()) - remaining)
+		n := 0
+		for i := 0; i < 10000; i++ {
+			if lc.needsRefresh(env) {
+				n++
+			}
+		}
+		ratio := float64(n) / 10000
+		want := math.Exp(-remaining.Seconds() / 10)
+		if math.Abs(ratio-want) > 0.03 || ratio <= prev {
+			t.Errorf("剩余 %v 时期望刷新概率约为 %.3f, 实际 %.3f", remaining, want, ratio)
+		}
+		prev = ratio
+	}
+
+	// 未启用XFetch时写入原始值
+	lc = NewLoadingCache(cache, LoadingCacheOptions{Clock: clock})
+	_, _ = lc.GetOrLoad(context.Background(), "plain", time.Minute, func(ctx context.Context) (any, error) {
+		return "v", nil
+	})
+	if raw, _ := cache.Get("plain"); raw != "v" {
+		t.Errorf("期望未启用XFetch时不附带头部, 实际 %q", raw)
+	}
+
+	// 恰好以魔数开头的普通值原样返回
+	for _, raw := range []string{loadEnvelopeMagic, loadEnvelopeMagic + strings.Repeat("x", loadEnvelopeSize)} {
+		if value, meta := decodeEnvelope(raw); value != raw || meta != (loadMeta{}) {
+			t.Errorf("期望 %q 原样返回, 实际 %q %+v", raw, value, meta)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package go_cache
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newArenaTestCache 创建使用ArenaStorage的内存缓存
+func newArenaTestCache() *MemoryCache {
+	return NewMemoryCacheWithOptions(MemoryCacheOptions{Storage: ArenaStorage, MaxBytes: 1 << 20})
+}
+
+func TestMemoryCache_ArenaFIFO(t *testing.T) {
+	var evicted []string
+	// 每个条目 33+2+8=43 字节，最多容纳3个
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{
+		Storage:  ArenaStorage,
+		MaxBytes: 135,
+		OnEvicted: func(key, value string) {
+			evicted = append(evicted, key)
+		},
+	})
+	defer cache.Close()
+
+	_ = cache.Set("k1", "12345678", 0)
+	_ = cache.Set("k2", "12345678", 0)
+	_ = cache.Set("k3", "12345678", 0)
+	// 覆盖写入在环尾追加新条目，旧条目失效后k1成为最旧的有效条目
+	_ = cache.Set("k2", "87654321", 0)
+
+	if exists, _ := cache.Exists("k1"); exists {
+		t.Error("期望k1被淘汰")
+	}
+	if value, _ := cache.Get("k2"); value != "87654321" {
+		t.Errorf("期望k2为新值, 实际 %q", value)
+	}
+	if fmt.Sprint(evicted) != "[k1]" {
+		t.Errorf("期望淘汰回调收到 [k1], 实际 %v", evicted)
+	}
+
+	// 绕回环头后仍然可以正常读写
+	for i := 0; i < 20; i++ {
+		_ = cache.Set(fmt.Sprintf("n%d", i%10), "12345678", 0)
+	}
+	keys, _ := cache.Keys("*")
+	if len(keys) != 3 {
+		t.Errorf("期望保留3个条目, 实际 %v", keys)
+	}
+	for _, key := range keys {
+		if _, err := cache.Get(key); err != nil {
+			t.Errorf("期望 %s 可以读取, 实际 %v", key, err)
+		}
+	}
+
+	if err := cache.Set("big", strings.Repeat("x", 200), 0); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("期望超过容量的条目返回ErrInvalidParameter, 实际 %v", err)
+	}
+}
+
+func TestMemoryCache_ArenaMaxEntries(t *testing.T) {
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{Storage: ArenaStorage, MaxEntries: 10})
+	defer cache.Close()
+
+	for i := 0; i < 100; i++ {
+		_ = cache.Set(fmt.Sprintf("key%d", i), i, 0)
+	}
+	if n := cache.entries(); n != 10 {
+		t.Errorf("期望保留10个条目, 实际 %d", n)
+	}
+	if value, _ := cache.Get("key99"); value != "99" {
+		t.Errorf("期望最新写入的条目存在, 实际 %q", value)
+	}
+}
+
+func TestMemoryCache_ArenaExpiration(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{Storage: ArenaStorage, DisableJanitor: true, Clock: clock})
+	defer cache.Close()
+
+	_ = cache.SetWithTags("short", "v", 5*time.Millisecond, "t")
+	_ = cache.Set("long", "v", time.Hour)
+	if ttl, _ := cache.TTL("long"); ttl <= 59*time.Minute {
+		t.Errorf("期望TTL接近1小时, 实际 %v", ttl)
+	}
+	_ = cache.Expire("long", 0)
+	if ttl, _ := cache.TTL("long"); ttl != -1 {
+		t.Errorf("期望取消过期时间后TTL为-1, 实际 %v", ttl)
+	}
+
+	clock.Advance(10 * time.Millisecond)
+	if _, err := cache.Get("short"); err != ErrKeyNotFound {
+		t.Errorf("期望过期的键不可读取, 实际 %v", err)
+	}
+	cache.DeleteExpired()
+	if n := cache.entries(); n != 1 {
+		t.Errorf("期望清理后剩1个条目, 实际 %d", n)
+	}
+	if len(cache.shards[0].tags) != 0 {
+		t.Errorf("期望清理后标签索引为空, 实际 %v", cache.shards[0].tags)
+	}
+}
+
+func TestMemoryCache_ArenaRandom(t *testing.T) {
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{Storage: ArenaStorage, MaxBytes: 4096})
+	defer cache.Close()
+
+	// 随机读写删除，命中时必须是最后一次写入的值
+	latest := make(map[string]string)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		key := fmt.Sprintf("key%d", rng.Intn(100))
+		switch rng.Intn(3) {
+		case 0:
+			value := strings.Repeat("v", rng.Intn(100))
+			if err := cache.Set(key, value, 0); err != nil {
+				t.Fatalf("写入失败: %v", err)
+			}
+			latest[key] = value
+		case 1:
+			_ = cache.Delete(key)
+			delete(latest, key)
+		default:
+			value, err := cache.Get(key)
+			if err == nil && value != latest[key] {
+				t.Fatalf("第%d次操作读取 %s 期望 %q, 实际 %q", i, key, latest[key], value)
+			}
+			if _, ok := latest[key]; !ok && err == nil {
+				t.Fatalf("第%d次操作读取到已删除的键 %s", i, key)
+			}
+		}
+	}
+
+	store := cache.shards[0].store.(*arenaStore)
+	live := 0
+	store.forEach(func(item *cacheItem) { live++ })
+	if live != store.len() || live != len(store.index) {
+		t.Errorf("有效条目数不一致: 遍历 %d, 计数 %d, 索引 %d", live, store.len(), len(store.index))
+	}
+}
+
+// BenchmarkMemoryCache_GC 比较大量条目下两种存储方式的GC开销，
+// ns/op为一次完整GC的耗时，pause-ns为每次GC的STW暂停时间
+func BenchmarkMemoryCache_GC(b *testing.B) {
+	const entries = 1000000
+	for _, storage := range []MemoryStorage{MapStorage, ArenaStorage} {
+		b.Run(string(storage), func(b *testing.B) {
+			opts := MemoryCacheOptions{Storage: storage, Shards: 16}
+			if storage == ArenaStorage {
+				opts.MaxBytes = 256 << 20
+			}
+			cache := NewMemoryCacheWithOptions(opts)
+			defer cache.Close()
+			for i := 0; i < entries; i++ {
+				_ = cache.Set(fmt.Sprintf("key%d", i), "value", time.Hour)
+			}
+			runtime.GC()
+
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				runtime.GC()
+			}
+			b.StopTimer()
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(after.PauseTotalNs-before.PauseTotalNs)/float64(b.N), "pause-ns")
+			runtime.KeepAlive(cache)
+		})
+	}
+}
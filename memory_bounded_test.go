@@ -0,0 +1,100 @@
+package go_cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestMemoryCache_MaxEntries(t *testing.T) {
+	var mu sync.Mutex
+	var evicted []string
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{
+		MaxEntries: 3,
+		OnEvicted: func(key, value string) {
+			mu.Lock()
+			evicted = append(evicted, key+"="+value)
+			mu.Unlock()
+		},
+	})
+	defer cache.Close()
+
+	_ = cache.Set("a", "1", 0)
+	_ = cache.Set("b", "2", 0)
+	_ = cache.Set("c", "3", 0)
+	// 访问a后，b成为最久未访问的条目
+	_, _ = cache.Get("a")
+	_ = cache.Set("d", "4", 0)
+
+	if exists, _ := cache.Exists("b"); exists {
+		t.Error("期望b被淘汰")
+	}
+	for _, key := range []string{"a", "c", "d"} {
+		if exists, _ := cache.Exists(key); !exists {
+			t.Errorf("期望 %s 仍然存在", key)
+		}
+	}
+	if len(evicted) != 1 || evicted[0] != "b=2" {
+		t.Errorf("期望淘汰回调收到 [b=2], 实际 %v", evicted)
+	}
+
+	// 覆盖已存在的键不触发淘汰
+	_ = cache.Set("a", "10", 0)
+	if len(evicted) != 1 {
+		t.Errorf("期望覆盖写入不淘汰, 实际 %v", evicted)
+	}
+}
+
+func TestMemoryCache_MaxBytes(t *testing.T) {
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{MaxBytes: 20})
+	defer cache.Close()
+
+	// 每个条目 2+8=10 字节
+	_ = cache.Set("k1", "12345678", 0)
+	_ = cache.Set("k2", "12345678", 0)
+	_ = cache.Set("k3", "12345678", 0)
+	if exists, _ := cache.Exists("k1"); exists {
+		t.Error("期望k1被淘汰")
+	}
+	if cache.shards[0].bytes != 20 {
+		t.Errorf("期望占用20字节, 实际 %d", cache.shards[0].bytes)
+	}
+
+	// 计数器增长同样计入容量
+	_ = cache.Set("k2", "1", 0)
+	if _, err := cache.IncrBy("k2", 1000000000, 0); err != nil {
+		t.Fatalf("自增失败: %v", err)
+	}
+	if cache.shards[0].bytes > 20 {
+		t.Errorf("期望占用不超过20字节, 实际 %d", cache.shards[0].bytes)
+	}
+
+	// 删除和清空释放容量
+	_ = cache.Delete("k3")
+	_ = cache.Clear()
+	if cache.shards[0].bytes != 0 || cache.shards[0].store.len() != 0 {
+		t.Errorf("期望清空后不占用容量, 实际 %d 字节 %d 条", cache.shards[0].bytes, cache.shards[0].store.len())
+	}
+}
+
+func TestMemoryCache_BoundedConcurrent(t *testing.T) {
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{MaxEntries: 100})
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				key := fmt.Sprintf("k%d_%d", i, j%200)
+				_ = cache.Set(key, j, 0)
+				_, _ = cache.Get(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if cache.shards[0].store.len() > 100 {
+		t.Errorf("期望不超过100条, 实际 %d", cache.shards[0].store.len())
+	}
+}
@@ -1,165 +1,661 @@
 package go_cache
 
 import (
-	"sync"
+	"context"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// MemoryCache 实现了基于内存的缓存
+// MemoryCache 实现了基于内存的缓存。
+// 键按哈希分布到若干分片，每个分片拥有独立的锁，默认只有一个分片
 type MemoryCache struct {
-	data map[string]*cacheItem
-	mu   sync.RWMutex
-	stop chan bool
+	shards   []*memoryShard
+	mask     uint32 // 分片数减1，分片数总是2的幂
+	stop     func() // 停止后台清理
+	codec    Codec
+	notifier *evictNotifier
+	clock    Clock
 }
 
+// MemoryCacheOptions 内存缓存的可选配置
+type MemoryCacheOptions struct {
+	// Codec 非字符串值的序列化方式，默认为JSONCodec
+	Codec Codec
+
+	// MaxEntries 最多保存的条目数，超出时按淘汰策略淘汰条目，0表示不限制
+	MaxEntries int
+
+	// MaxBytes 所有键和值占用的最大字节数，超出时按淘汰策略淘汰条目，0表示不限制
+	MaxBytes int64
+
+	// EvictionPolicy 内置淘汰策略的类型，默认为LRU，无法识别的类型也按LRU处理
+	EvictionPolicy EvictionPolicyType
+
+	// NewEvictionPolicy 自定义淘汰策略，设置后忽略EvictionPolicy。
+	// capacity为每个分片的条目上限，只限制字节数时为0；每个分片各自创建策略，Clear时会重新创建
+	NewEvictionPolicy func(capacity int) EvictionPolicy
+
+	// OnEvicted 条目因容量限制被淘汰时调用，调用时不持有缓存锁。
+	// 需要其他移除原因时使用OnEvict
+	OnEvicted func(key, value string)
+
+	// Shards 分片数，向上取整为2的幂，0或1表示不分片。
+	// 分片后MaxEntries和MaxBytes平均分配给各分片，每个分片独立淘汰
+	Shards int
+
+	// JanitorInterval 后台清理过期条目的间隔，默认为1秒
+	JanitorInterval time.Duration
+
+	// DisableJanitor 不启动后台清理协程。过期的条目仍然不可读取，
+	// 但会一直占用内存直到被覆盖、删除或调用DeleteExpired
+	DisableJanitor bool
+
+	// Storage 条目的存储方式，默认为MapStorage
+	Storage MemoryStorage
+
+	// Clock 读取当前时间和驱动后台清理的时钟，默认为系统时钟，测试时可以使用FakeClock
+	Clock Clock
+}
+
+// MemoryStorage 内存缓存保存条目的方式
+type MemoryStorage string
+
+const (
+	// MapStorage 每个条目是一个独立的Go对象，支持所有淘汰策略
+	MapStorage MemoryStorage = "map"
+
+	// ArenaStorage 条目保存在预先分配的字节数组中，索引不包含指针，条目很多时GC开销更小。
+	// MaxBytes为所有字节数组的总大小（默认64MB），包括每个条目33字节的头部；
+	// 空间不足时按写入顺序淘汰最旧的条目（FIFO），忽略EvictionPolicy和NewEvictionPolicy
+	ArenaStorage MemoryStorage = "arena"
+)
+
+const (
+	// defaultJanitorInterval 默认的过期清理间隔
+	defaultJanitorInterval = time.Second
+	// janitorBatchSize 清理时每个分片单次加锁最多删除的条目数
+	janitorBatchSize = 1024
+	// defaultArenaBytes ArenaStorage未设置MaxBytes时所有字节数组的总大小
+	defaultArenaBytes = 64 << 20
+)
+
 // cacheItem 表示缓存中的一个项目
 type cacheItem struct {
+	key        string
 	value      string
 	expiration time.Time
+	tags       []string
+	sliding    time.Duration // 滑动过期时长，大于0时每次读取将过期时间延长到读取时刻加sliding
+	index      int           // 在mapStore过期时间索引中的位置
 }
 
 // NewMemoryCache 创建一个新的内存缓存实例
 func NewMemoryCache() *MemoryCache {
+	return NewMemoryCacheWithOptions(MemoryCacheOptions{})
+}
+
+// NewMemoryCacheWithOptions 使用指定配置创建一个新的内存缓存实例
+func NewMemoryCacheWithOptions(opts MemoryCacheOptions) *MemoryCache {
+	if opts.Codec == nil {
+		opts.Codec = JSONCodec{}
+	}
+	n := 1
+	for n < opts.Shards {
+		n <<= 1
+	}
+
+	// 容量上限向上取整分配给各分片
+	maxEntries := (opts.MaxEntries + n - 1) / n
+	maxBytes := (opts.MaxBytes + int64(n) - 1) / int64(n)
+	var newPolicy func() EvictionPolicy
+	if opts.Storage != ArenaStorage && (opts.MaxEntries > 0 || opts.MaxBytes > 0) {
+		newPolicy = func() EvictionPolicy {
+			if opts.NewEvictionPolicy != nil {
+				return opts.NewEvictionPolicy(maxEntries)
+			}
+			policy, err := NewEvictionPolicy(opts.EvictionPolicy, maxEntries)
+			if err != nil {
+				return newLRUPolicy()
+			}
+			return policy
+		}
+	}
+
 	cache := &MemoryCache{
-		data: make(map[string]*cacheItem),
-		stop: make(chan bool),
+		shards:   make([]*memoryShard, n),
+		mask:     uint32(n - 1),
+		stop:     func() {},
+		codec:    opts.Codec,
+		notifier: &evictNotifier{},
+		clock:    clockOrSystem(opts.Clock),
+	}
+	if opts.OnEvicted != nil {
+		onEvicted := opts.OnEvicted
+		cache.notifier.add(func(key, value string, reason EvictReason) {
+			if reason == EvictReasonEvicted {
+				onEvicted(key, value)
+			}
+		})
+	}
+	arenaBytes := maxBytes
+	if arenaBytes == 0 {
+		arenaBytes = defaultArenaBytes / int64(n)
+	}
+	for i := range cache.shards {
+		if opts.Storage == ArenaStorage {
+			// arena自行按FIFO限制容量
+			cache.shards[i] = newMemoryShard(newArenaStore(arenaBytes, maxEntries), 0, 0, nil, cache.notifier, cache.clock)
+			continue
+		}
+		cache.shards[i] = newMemoryShard(newMapStore(), maxEntries, maxBytes, newPolicy, cache.notifier, cache.clock)
 	}
 
 	// 启动过期清理协程
-	go cache.cleanup()
+	if !opts.DisableJanitor {
+		if opts.JanitorInterval <= 0 {
+			opts.JanitorInterval = defaultJanitorInterval
+		}
+		cache.stop = cache.clock.Every(opts.JanitorInterval, cache.DeleteExpired)
+	}
 
 	return cache
 }
 
-// Set 将键值对存储到缓存中，并设置过期时间
-func (m *MemoryCache) Set(key string, value interface{}, expiration time.Duration) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// shardIndex 使用FNV-1a计算键所在的分片
+func (m *MemoryCache) shardIndex(key string) uint32 {
+	if m.mask == 0 {
+		return 0
+	}
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return h & m.mask
+}
+
+// shard 返回键所在的分片
+func (m *MemoryCache) shard(key string) *memoryShard {
+	return m.shards[m.shardIndex(key)]
+}
 
-	var expirationTime time.Time
-	if expiration > 0 {
-		expirationTime = time.Now().Add(expiration)
+// groupKeys 将键按所在分片分组
+func (m *MemoryCache) groupKeys(keys []string) [][]string {
+	groups := make([][]string, len(m.shards))
+	for _, key := range keys {
+		i := m.shardIndex(key)
+		groups[i] = append(groups[i], key)
 	}
+	return groups
+}
 
-	m.data[key] = &cacheItem{
-		value:      ToString(value),
-		expiration: expirationTime,
+// Set 将键值对存储到缓存中，并设置过期时间
+func (m *MemoryCache) Set(key string, value interface{}, expiration time.Duration) error {
+	str, err := encodeValue(m.codec, value)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.unlock()
+
+	return s.storeLocked(key, &cacheItem{
+		value:      str,
+		expiration: expireAt(m.clock.Now(), expiration),
+	})
 }
 
 // Get 从缓存中获取指定键的值
 func (m *MemoryCache) Get(key string) (string, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	item, exists := m.data[key]
-	if !exists {
+	s := m.shard(key)
+	now := m.clock.Now()
+	unlock := s.lockRead()
+	item, ok := s.getLocked(key, now)
+	unlock()
+	if !ok {
 		return "", ErrKeyNotFound
 	}
+	if item.sliding > 0 {
+		// 只有滑动过期的条目才在读取后加写锁更新过期时间
+		s.mu.Lock()
+		s.slideLocked(key, now)
+		s.unlock()
+	}
+	return item.value, nil
+}
+
+// GetAndTouch 获取指定键的值，并将过期时间重置为ttl，ttl<=0表示永不过期
+func (m *MemoryCache) GetAndTouch(key string, ttl time.Duration) (string, error) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.unlock()
 
-	// 检查是否过期
-	if !item.expiration.IsZero() && time.Now().After(item.expiration) {
+	now := m.clock.Now()
+	item, ok := s.getLocked(key, now)
+	if !ok {
 		return "", ErrKeyNotFound
 	}
-
+	s.setExpirationLocked(item, expireAt(now, ttl))
 	return item.value, nil
 }
 
+// SetSliding 写入滑动过期的键值对，之后每次Get或GetMulti命中都将过期时间重置为读取时刻加ttl
+func (m *MemoryCache) SetSliding(key string, value interface{}, ttl time.Duration) error {
+	str, err := encodeValue(m.codec, value)
+	if err != nil {
+		return err
+	}
+
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.unlock()
+
+	return s.storeLocked(key, &cacheItem{
+		value:      str,
+		expiration: expireAt(m.clock.Now(), ttl),
+		sliding:    max(ttl, 0),
+	})
+}
+
 // Delete 从缓存中删除指定键
 func (m *MemoryCache) Delete(key string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.unlock()
 
-	delete(m.data, key)
+	s.removeLocked(key)
 	return nil
 }
 
 // Exists 检查指定键是否存在于缓存中
 func (m *MemoryCache) Exists(key string) (bool, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	item, exists := m.data[key]
-	if !exists {
-		return false, nil
-	}
-
-	// 检查是否过期
-	if !item.expiration.IsZero() && time.Now().After(item.expiration) {
-		return false, nil
-	}
+	s := m.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	return true, nil
+	_, exists := s.lookupLocked(key, m.clock.Now())
+	return exists, nil
 }
 
 // Expire 设置键的过期时间
 func (m *MemoryCache) Expire(key string, expiration time.Duration) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.unlock()
 
-	item, exists := m.data[key]
+	now := m.clock.Now()
+	item, exists := s.lookupLocked(key, now)
 	if !exists {
 		return ErrKeyNotFound
 	}
 
-	if expiration > 0 {
-		item.expiration = time.Now().Add(expiration)
-	} else {
-		item.expiration = time.Time{}
-	}
-
+	s.setExpirationLocked(item, expireAt(now, expiration))
 	return nil
 }
 
 // TTL 获取键的剩余生存时间
 func (m *MemoryCache) TTL(key string) (time.Duration, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	s := m.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	item, exists := m.data[key]
+	now := m.clock.Now()
+	item, exists := s.lookupLocked(key, now)
 	if !exists {
 		return 0, ErrKeyNotFound
 	}
 
-	// 检查是否过期
-	if !item.expiration.IsZero() && time.Now().After(item.expiration) {
-		return 0, ErrKeyNotFound
-	}
-
 	if item.expiration.IsZero() {
 		// 永不过期
 		return -1, nil
 	}
 
-	return time.Until(item.expiration), nil
+	return item.expiration.Sub(now), nil
 }
 
-// Close 关闭缓存连接
+// OnEvict 注册监听函数，条目因过期、淘汰、删除或覆盖离开缓存时调用，调用时不持有缓存锁。
+// 已过期但尚未清理的条目被覆盖或删除时，原因为EvictReasonExpired
+func (m *MemoryCache) OnEvict(fn func(key, value string, reason EvictReason)) {
+	m.notifier.add(fn)
+}
+
+// Codec 返回缓存使用的序列化方式
+func (m *MemoryCache) Codec() Codec {
+	return m.codec
+}
+
+// Close 停止后台清理
 func (m *MemoryCache) Close() error {
-	close(m.stop)
+	m.stop()
 	return nil
 }
 
-// cleanup 定期清理过期的缓存项
-func (m *MemoryCache) cleanup() {
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
+// DeleteExpired 删除所有已过期的缓存项。按过期时间索引逐个分片清理，开销与过期的条目数成正比，
+// 每个分片单次加锁最多删除janitorBatchSize个条目，避免长时间阻塞读写
+func (m *MemoryCache) DeleteExpired() {
+	for _, s := range m.shards {
+		for more := true; more; {
+			s.mu.Lock()
+			more = s.expireLocked(m.clock.Now(), janitorBatchSize)
+			s.unlock()
+		}
+	}
+}
+
+// expired 判断缓存项在now时刻是否已过期
+func (item *cacheItem) expired(now time.Time) bool {
+	return !item.expiration.IsZero() && now.After(item.expiration)
+}
 
-	for {
-		select {
-		case <-ticker.C:
-			m.mu.Lock()
-			now := time.Now()
-			for key, item := range m.data {
-				if !item.expiration.IsZero() && now.After(item.expiration) {
-					delete(m.data, key)
+// SetCtx 将键值对存储到缓存中，ctx已取消时直接返回
+func (m *MemoryCache) SetCtx(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.Set(key, value, expiration)
+}
+
+// GetCtx 从缓存中获取指定键的值，ctx已取消时直接返回
+func (m *MemoryCache) GetCtx(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return m.Get(key)
+}
+
+// DeleteCtx 从缓存中删除指定键，ctx已取消时直接返回
+func (m *MemoryCache) DeleteCtx(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.Delete(key)
+}
+
+// ExistsCtx 检查指定键是否存在于缓存中，ctx已取消时直接返回
+func (m *MemoryCache) ExistsCtx(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return m.Exists(key)
+}
+
+// ExpireCtx 设置键的过期时间，ctx已取消时直接返回
+func (m *MemoryCache) ExpireCtx(ctx context.Context, key string, expiration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.Expire(key, expiration)
+}
+
+// TTLCtx 获取键的剩余生存时间，ctx已取消时直接返回
+func (m *MemoryCache) TTLCtx(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return m.TTL(key)
+}
+
+// GetMulti 批量获取指定键的值，每个分片只加一次锁；命中滑动过期的条目时再加一次写锁更新过期时间
+func (m *MemoryCache) GetMulti(keys []string) (map[string]string, error) {
+	now := m.clock.Now()
+	values := make(map[string]string, len(keys))
+	for i, group := range m.groupKeys(keys) {
+		if len(group) == 0 {
+			continue
+		}
+		s := m.shards[i]
+		var sliding []string
+		unlock := s.lockRead()
+		for _, key := range group {
+			if item, ok := s.getLocked(key, now); ok {
+				values[key] = item.value
+				if item.sliding > 0 {
+					sliding = append(sliding, key)
 				}
 			}
-			m.mu.Unlock()
-		case <-m.stop:
-			return
 		}
+		unlock()
+
+		if len(sliding) > 0 {
+			s.mu.Lock()
+			for _, key := range sliding {
+				s.slideLocked(key, now)
+			}
+			s.unlock()
+		}
+	}
+	return values, nil
+}
+
+// SetMulti 批量将键值对存储到缓存中，序列化在加锁前完成，每个分片只加一次写锁
+func (m *MemoryCache) SetMulti(items map[string]interface{}, expiration time.Duration) error {
+	errs := make(map[string]error)
+	encoded := encodeItems(m.codec, items, errs)
+
+	keys := make([]string, 0, len(encoded))
+	for key := range encoded {
+		keys = append(keys, key)
+	}
+	expirationTime := expireAt(m.clock.Now(), expiration)
+	for i, group := range m.groupKeys(keys) {
+		if len(group) == 0 {
+			continue
+		}
+		s := m.shards[i]
+		s.mu.Lock()
+		for _, key := range group {
+			err := s.storeLocked(key, &cacheItem{
+				value:      encoded[key],
+				expiration: expirationTime,
+			})
+			if err != nil {
+				errs[key] = err
+			}
+		}
+		s.unlock()
+	}
+	return newBatchError(errs)
+}
+
+// DeleteMulti 批量删除指定键，每个分片只加一次写锁
+func (m *MemoryCache) DeleteMulti(keys []string) error {
+	for i, group := range m.groupKeys(keys) {
+		if len(group) == 0 {
+			continue
+		}
+		s := m.shards[i]
+		s.mu.Lock()
+		for _, key := range group {
+			s.removeLocked(key)
+		}
+		s.unlock()
+	}
+	return nil
+}
+
+// Incr 将计数器加1
+func (m *MemoryCache) Incr(key string, expiration time.Duration) (int64, error) {
+	return m.IncrBy(key, 1, expiration)
+}
+
+// Decr 将计数器减1
+func (m *MemoryCache) Decr(key string, expiration time.Duration) (int64, error) {
+	return m.IncrBy(key, -1, expiration)
+}
+
+// DecrBy 将计数器减delta
+func (m *MemoryCache) DecrBy(key string, delta int64, expiration time.Duration) (int64, error) {
+	if delta == math.MinInt64 {
+		return 0, ErrNotInteger
+	}
+	return m.IncrBy(key, -delta, expiration)
+}
+
+// IncrBy 在写锁内将计数器加delta，计数器不存在时以expiration创建
+func (m *MemoryCache) IncrBy(key string, delta int64, expiration time.Duration) (int64, error) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.unlock()
+
+	now := m.clock.Now()
+	if item, exists := s.lookupLocked(key, now); exists {
+		n, err := incrValue(item.value, delta)
+		if err != nil {
+			return 0, err
+		}
+		if err := s.updateValueLocked(item, strconv.FormatInt(n, 10)); err != nil {
+			return 0, err
+		}
+		return n, nil
+	}
+
+	err := s.storeLocked(key, &cacheItem{
+		value:      strconv.FormatInt(delta, 10),
+		expiration: expireAt(now, expiration),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return delta, nil
+}
+
+// SetNX 仅当键不存在时写入
+func (m *MemoryCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return m.setIf(key, value, expiration, func(item *cacheItem, exists bool) bool {
+		return !exists
+	})
+}
+
+// SetXX 仅当键已存在时写入
+func (m *MemoryCache) SetXX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return m.setIf(key, value, expiration, func(item *cacheItem, exists bool) bool {
+		return exists
+	})
+}
+
+// CompareAndSwap 仅当键的当前值等于old时写入new
+func (m *MemoryCache) CompareAndSwap(key string, old, new interface{}, expiration time.Duration) (bool, error) {
+	oldStr, err := encodeValue(m.codec, old)
+	if err != nil {
+		return false, err
+	}
+	return m.setIf(key, new, expiration, func(item *cacheItem, exists bool) bool {
+		return exists && item.value == oldStr
+	})
+}
+
+// setIf 在写锁内检查条件，条件满足时写入
+func (m *MemoryCache) setIf(key string, value interface{}, expiration time.Duration, cond func(item *cacheItem, exists bool) bool) (bool, error) {
+	str, err := encodeValue(m.codec, value)
+	if err != nil {
+		return false, err
+	}
+
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.unlock()
+
+	now := m.clock.Now()
+	item, exists := s.lookupLocked(key, now)
+	if !cond(item, exists) {
+		return false, nil
+	}
+
+	err = s.storeLocked(key, &cacheItem{
+		value:      str,
+		expiration: expireAt(now, expiration),
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Keys 返回匹配pattern的所有未过期键
+func (m *MemoryCache) Keys(pattern string) ([]string, error) {
+	return m.keysMatching(func(key string) bool {
+		return matchPattern(pattern, key)
+	}), nil
+}
+
+// Scan 返回以prefix开头的键的迭代器，迭代的是调用时的键快照
+func (m *MemoryCache) Scan(prefix string) KeyIterator {
+	return newSliceKeyIterator(m.keysMatching(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	}), nil)
+}
+
+// keysMatching 逐个分片在读锁内收集满足条件的未过期键
+func (m *MemoryCache) keysMatching(match func(key string) bool) []string {
+	now := m.clock.Now()
+	var keys []string
+	for _, s := range m.shards {
+		s.mu.RLock()
+		s.store.forEach(func(item *cacheItem) {
+			if !item.expired(now) && match(item.key) {
+				keys = append(keys, item.key)
+			}
+		})
+		s.mu.RUnlock()
+	}
+	return keys
+}
+
+// Clear 删除缓存中的所有键
+func (m *MemoryCache) Clear() error {
+	for _, s := range m.shards {
+		s.mu.Lock()
+		s.clearLocked()
+		s.unlock()
+	}
+	return nil
+}
+
+// SetWithTags 将键值对存储到缓存中并关联标签
+func (m *MemoryCache) SetWithTags(key string, value interface{}, expiration time.Duration, tags ...string) error {
+	str, err := encodeValue(m.codec, value)
+	if err != nil {
+		return err
+	}
+
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.unlock()
+
+	return s.storeLocked(key, &cacheItem{
+		value:      str,
+		expiration: expireAt(m.clock.Now(), expiration),
+		tags:       uniqueKeys(append([]string(nil), tags...)),
+	})
+}
+
+// InvalidateTag 删除关联了tag的所有键
+func (m *MemoryCache) InvalidateTag(tag string) error {
+	for _, s := range m.shards {
+		s.mu.Lock()
+		for key := range s.tags[tag] {
+			s.removeLocked(key)
+		}
+		s.unlock()
+	}
+	return nil
+}
+
+// TagKeys 返回关联了tag的所有未过期键
+func (m *MemoryCache) TagKeys(tag string) ([]string, error) {
+	now := m.clock.Now()
+	var keys []string
+	for _, s := range m.shards {
+		s.mu.RLock()
+		for key := range s.tags[tag] {
+			if _, exists := s.lookupLocked(key, now); exists {
+				keys = append(keys, key)
+			}
+		}
+		s.mu.RUnlock()
 	}
+	return keys, nil
 }
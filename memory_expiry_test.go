@@ -0,0 +1,113 @@
+package go_cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache_Janitor(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{JanitorInterval: time.Second, Shards: 4, Clock: clock})
+	defer cache.Close()
+
+	for i := 0; i < 100; i++ {
+		_ = cache.Set(fmt.Sprintf("short%d", i), i, 1500*time.Millisecond)
+	}
+	_ = cache.Set("forever", "v", 0)
+
+	// 第一次清理时条目还未过期
+	clock.Advance(time.Second)
+	if n := cache.entries(); n != 101 {
+		t.Errorf("期望第一次清理后仍有101个条目, 实际 %d", n)
+	}
+	clock.Advance(time.Second)
+	if n := cache.entries(); n != 1 {
+		t.Errorf("期望后台清理后只剩1个条目, 实际 %d", n)
+	}
+	if exists, _ := cache.Exists("forever"); !exists {
+		t.Error("期望永不过期的键仍然存在")
+	}
+}
+
+func TestMemoryCache_DisableJanitor(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{DisableJanitor: true, Clock: clock})
+	defer cache.Close()
+
+	_ = cache.Set("key", "v", time.Millisecond)
+	clock.Advance(time.Hour)
+
+	if _, err := cache.Get("key"); err != ErrKeyNotFound {
+		t.Errorf("期望过期的键不可读取, 实际 %v", err)
+	}
+	if n := cache.entries(); n != 1 {
+		t.Errorf("期望关闭后台清理时过期条目仍占用内存, 实际 %d", n)
+	}
+	cache.DeleteExpired()
+	if n := cache.entries(); n != 0 {
+		t.Errorf("期望DeleteExpired后没有条目, 实际 %d", n)
+	}
+}
+
+func TestMemoryCache_ExpiryIndex(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{DisableJanitor: true, Clock: clock})
+	defer cache.Close()
+	s := cache.shards[0].store.(*mapStore)
+
+	_ = cache.Set("a", "1", time.Hour)
+	_ = cache.Set("b", "2", time.Hour)
+	_ = cache.Set("c", "3", 0)
+	if len(s.ttl) != 2 {
+		t.Errorf("期望索引中有2个条目, 实际 %d", len(s.ttl))
+	}
+
+	// 覆盖写入和取消过期时间都要更新索引
+	_ = cache.Set("a", "1", 0)
+	_ = cache.Expire("b", 0)
+	if len(s.ttl) != 0 {
+		t.Errorf("期望索引为空, 实际 %d", len(s.ttl))
+	}
+
+	_ = cache.Expire("c", time.Millisecond)
+	_ = cache.Expire("a", time.Hour)
+	_ = cache.Expire("b", time.Millisecond)
+	_ = cache.Expire("a", time.Millisecond)
+	_ = cache.Delete("b")
+	clock.Advance(time.Second)
+	cache.DeleteExpired()
+	if n := cache.entries(); n != 0 || len(s.ttl) != 0 {
+		t.Errorf("期望所有条目过期被删除, 实际 %d 个条目, 索引 %d", n, len(s.ttl))
+	}
+}
+
+// entries 返回所有分片中的条目数，包括已过期但未清理的条目
+func (m *MemoryCache) entries() int {
+	n := 0
+	for _, s := range m.shards {
+		s.mu.RLock()
+		n += s.store.len()
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+// BenchmarkMemoryCache_DeleteExpired 大量永不过期的条目中只有少量过期，清理开销只与过期的条目数有关
+func BenchmarkMemoryCache_DeleteExpired(b *testing.B) {
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{DisableJanitor: true})
+	defer cache.Close()
+	for i := 0; i < 100000; i++ {
+		_ = cache.Set(fmt.Sprintf("key%d", i), i, 0)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for j := 0; j < 10; j++ {
+			_ = cache.Set(fmt.Sprintf("short%d", j), j, time.Nanosecond)
+		}
+		b.StartTimer()
+		cache.DeleteExpired()
+	}
+}
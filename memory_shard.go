@@ -0,0 +1,227 @@
+package go_cache
+
+import (
+	"sync"
+	"time"
+)
+
+// memoryShard 是MemoryCache的一个分片，拥有独立的锁、数据、标签索引和淘汰策略
+type memoryShard struct {
+	store itemStore
+	tags  map[string]map[string]struct{} // 标签到键的索引
+	mu    sync.RWMutex
+
+	// 容量限制，policy为nil时不限制容量；arena存储自行按FIFO限制容量
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	policy     EvictionPolicy
+	newPolicy  func() EvictionPolicy
+
+	notifier *evictNotifier
+	events   []evictEvent // 持锁期间产生、等待在锁外回调的移除事件
+	clock    Clock
+}
+
+func newMemoryShard(store itemStore, maxEntries int, maxBytes int64, newPolicy func() EvictionPolicy, notifier *evictNotifier, clock Clock) *memoryShard {
+	s := &memoryShard{
+		store:      store,
+		tags:       make(map[string]map[string]struct{}),
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		newPolicy:  newPolicy,
+		notifier:   notifier,
+		clock:      clock,
+	}
+	if newPolicy != nil {
+		s.policy = newPolicy()
+	}
+	return s
+}
+
+// storeLocked 写入缓存项并维护标签索引和容量，调用方必须持有写锁
+func (s *memoryShard) storeLocked(key string, item *cacheItem) error {
+	item.key = key
+	old, evicted, err := s.store.put(item)
+	if err != nil {
+		return err
+	}
+	if old != nil {
+		s.forgetLocked(old)
+		s.queueLocked(old, EvictReasonReplaced)
+	}
+	for _, e := range evicted {
+		s.forgetLocked(e)
+		s.queueLocked(e, EvictReasonEvicted)
+	}
+	s.bytes += entrySize(key, item.value)
+	for _, tag := range item.tags {
+		keys, ok := s.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			s.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	if s.policy != nil {
+		s.policy.Add(key)
+		s.evictLocked()
+	}
+	return nil
+}
+
+// updateValueLocked 修改已存在缓存项的值，保留过期时间和标签
+func (s *memoryShard) updateValueLocked(item *cacheItem, value string) error {
+	updated := *item
+	updated.value = value
+	return s.storeLocked(item.key, &updated)
+}
+
+// removeLocked 删除缓存项并维护标签索引和容量，调用方必须持有写锁
+func (s *memoryShard) removeLocked(key string) {
+	item, exists := s.store.remove(key)
+	if !exists {
+		return
+	}
+	s.forgetLocked(item)
+	s.queueLocked(item, EvictReasonDeleted)
+	if s.policy != nil {
+		s.policy.Remove(key)
+	}
+}
+
+// forgetLocked 缓存项离开存储后，更新标签索引和占用的字节数
+func (s *memoryShard) forgetLocked(item *cacheItem) {
+	s.untagLocked(item.key, item)
+	s.bytes -= entrySize(item.key, item.value)
+}
+
+// evictLocked 超出容量限制时按淘汰策略淘汰条目
+func (s *memoryShard) evictLocked() {
+	for (s.maxEntries > 0 && s.store.len() > s.maxEntries) || (s.maxBytes > 0 && s.bytes > s.maxBytes) {
+		key, ok := s.policy.Victim()
+		if !ok {
+			return
+		}
+		item, exists := s.store.remove(key)
+		if !exists {
+			continue
+		}
+		s.forgetLocked(item)
+		s.queueLocked(item, EvictReasonEvicted)
+	}
+}
+
+// queueLocked 记录离开缓存的条目，释放锁后回调；已过期的条目总是以EvictReasonExpired通知
+func (s *memoryShard) queueLocked(item *cacheItem, reason EvictReason) {
+	if !s.notifier.enabled() {
+		return
+	}
+	if item.expired(s.clock.Now()) {
+		reason = EvictReasonExpired
+	}
+	s.events = append(s.events, evictEvent{key: item.key, value: item.value, reason: reason})
+}
+
+// touchLocked 记录一次访问
+func (s *memoryShard) touchLocked(key string) {
+	if s.policy != nil {
+		s.policy.Access(key)
+	}
+}
+
+// lookupLocked 返回未过期的缓存项，不记录访问
+func (s *memoryShard) lookupLocked(key string, now time.Time) (*cacheItem, bool) {
+	item, exists := s.store.get(key)
+	if !exists || item.expired(now) {
+		return nil, false
+	}
+	return item, true
+}
+
+// getLocked 返回未过期的缓存项并记录访问
+func (s *memoryShard) getLocked(key string, now time.Time) (*cacheItem, bool) {
+	item, ok := s.lookupLocked(key, now)
+	if ok {
+		s.touchLocked(key)
+	}
+	return item, ok
+}
+
+// untagLocked 从标签索引中移除键
+func (s *memoryShard) untagLocked(key string, item *cacheItem) {
+	for _, tag := range item.tags {
+		if keys, ok := s.tags[tag]; ok {
+			delete(keys, key)
+			if len(keys) == 0 {
+				delete(s.tags, tag)
+			}
+		}
+	}
+}
+
+// clearLocked 删除分片中的所有键
+func (s *memoryShard) clearLocked() {
+	if s.notifier.enabled() {
+		s.store.forEach(func(item *cacheItem) {
+			s.queueLocked(item, EvictReasonDeleted)
+		})
+	}
+	s.store.reset()
+	s.tags = make(map[string]map[string]struct{})
+	s.bytes = 0
+	if s.policy != nil {
+		s.policy = s.newPolicy()
+	}
+}
+
+// expireLocked 删除已过期的缓存项，最多删除limit个，返回是否还有已过期的缓存项
+func (s *memoryShard) expireLocked(now time.Time, limit int) bool {
+	removed, more := s.store.popExpired(now, limit)
+	for _, item := range removed {
+		s.forgetLocked(item)
+		s.queueLocked(item, EvictReasonExpired)
+		if s.policy != nil {
+			s.policy.Remove(item.key)
+		}
+	}
+	return more
+}
+
+// setExpirationLocked 修改缓存项的过期时间
+func (s *memoryShard) setExpirationLocked(item *cacheItem, expiration time.Time) {
+	s.store.setExpiration(item, expiration)
+}
+
+// slideLocked 将滑动过期的缓存项的过期时间延长到now加滑动时长，调用方必须持有写锁。
+// 读锁释放后条目可能已被覆盖，因此重新查找
+func (s *memoryShard) slideLocked(key string, now time.Time) {
+	if item, ok := s.lookupLocked(key, now); ok && item.sliding > 0 {
+		s.setExpirationLocked(item, now.Add(item.sliding))
+	}
+}
+
+// lockRead 为读操作加锁。有容量限制时读取也要更新淘汰策略的状态，因此加写锁
+func (s *memoryShard) lockRead() func() {
+	if s.policy != nil {
+		s.mu.Lock()
+		return s.unlock
+	}
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+// unlock 释放写锁，并在锁外回调持锁期间产生的移除事件
+func (s *memoryShard) unlock() {
+	events := s.events
+	s.events = nil
+	s.mu.Unlock()
+
+	s.notifier.notify(events)
+}
+
+// entrySize 估算一个条目占用的字节数
+func entrySize(key, value string) int64 {
+	return int64(len(key) + len(value))
+}
@@ -0,0 +1,144 @@
+package go_cache
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestMemoryCache_Shards(t *testing.T) {
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{Shards: 5})
+	defer cache.Close()
+
+	if len(cache.shards) != 8 {
+		t.Fatalf("期望分片数向上取整为8, 实际 %d", len(cache.shards))
+	}
+
+	var want []string
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("key%d", i)
+		want = append(want, key)
+		_ = cache.SetWithTags(key, i, 0, "all")
+	}
+	used := 0
+	for _, s := range cache.shards {
+		if s.store.len() > 0 {
+			used++
+		}
+	}
+	if used < 2 {
+		t.Errorf("期望键分布到多个分片, 实际只用了 %d 个", used)
+	}
+
+	if value, err := cache.Get("key42"); err != nil || value != "42" {
+		t.Errorf("期望获取到 42, 实际 %q, %v", value, err)
+	}
+	keys, _ := cache.Keys("key*")
+	sort.Strings(keys)
+	sort.Strings(want)
+	if fmt.Sprint(keys) != fmt.Sprint(want) {
+		t.Errorf("期望Keys返回所有分片的 %d 个键, 实际 %d 个", len(want), len(keys))
+	}
+	values, _ := cache.GetMulti([]string{"key1", "key2", "key3", "missing"})
+	if len(values) != 3 {
+		t.Errorf("期望批量获取到3个值, 实际 %v", values)
+	}
+	if tagged, _ := cache.TagKeys("all"); len(tagged) != 200 {
+		t.Errorf("期望标签关联200个键, 实际 %d", len(tagged))
+	}
+
+	_ = cache.InvalidateTag("all")
+	if keys, _ := cache.Keys("*"); len(keys) != 0 {
+		t.Errorf("期望标签失效后没有键, 实际 %d 个", len(keys))
+	}
+}
+
+func TestMemoryCache_ShardedMaxEntries(t *testing.T) {
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{Shards: 4, MaxEntries: 100})
+	defer cache.Close()
+
+	for i := 0; i < 1000; i++ {
+		_ = cache.Set(fmt.Sprintf("key%d", i), i, 0)
+	}
+	total := 0
+	for _, s := range cache.shards {
+		if s.store.len() > 25 {
+			t.Errorf("期望每个分片不超过25条, 实际 %d", s.store.len())
+		}
+		total += s.store.len()
+	}
+	if total > 100 {
+		t.Errorf("期望总条目数不超过100, 实际 %d", total)
+	}
+}
+
+func TestMemoryCache_ShardedConcurrent(t *testing.T) {
+	cache := NewMemoryCacheWithOptions(MemoryCacheOptions{Shards: 16, MaxEntries: 256})
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				key := fmt.Sprintf("k%d", (i*j)%500)
+				switch j % 5 {
+				case 0:
+					_ = cache.SetMulti(map[string]interface{}{key: j, key + "x": j}, 0)
+				case 1:
+					_ = cache.Delete(key)
+				case 2:
+					_, _ = cache.Keys("k1*")
+				default:
+					_ = cache.Set(key, j, 0)
+					_, _ = cache.Get(key)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// BenchmarkMemoryCache_Parallel 比较不同分片数下9读1写的并发吞吐，
+// 使用 -cpu 1,2,4,8 观察吞吐随GOMAXPROCS的变化
+func BenchmarkMemoryCache_Parallel(b *testing.B) {
+	const keyCount = 1 << 14
+	keys := make([]string, keyCount)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+
+	for _, shards := range []int{1, 16, 64} {
+		for _, bounded := range []bool{false, true} {
+			name := fmt.Sprintf("shards=%d", shards)
+			opts := MemoryCacheOptions{Shards: shards}
+			if bounded {
+				name += "/bounded"
+				opts.MaxEntries = keyCount / 2
+			}
+			b.Run(name, func(b *testing.B) {
+				cache := NewMemoryCacheWithOptions(opts)
+				defer cache.Close()
+				for _, key := range keys {
+					_ = cache.Set(key, "value", 0)
+				}
+
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					rng := rand.New(rand.NewSource(rand.Int63()))
+					for pb.Next() {
+						key := keys[rng.Intn(keyCount)]
+						if rng.Intn(10) == 0 {
+							_ = cache.Set(key, "value", 0)
+						} else {
+							_, _ = cache.Get(key)
+						}
+					}
+				})
+			})
+		}
+	}
+}
@@ -1,25 +1,54 @@
 package go_cache
 
 import (
+	"context"
 	"time"
 )
 
 // MultiCache 组合多种缓存实现
 type MultiCache struct {
 	caches []Cache
+	codec  Codec
+}
+
+// MultiCacheOptions 组合缓存的可选配置
+type MultiCacheOptions struct {
+	// Codec 非字符串值的序列化方式，值只序列化一次后写入所有缓存，默认为JSONCodec
+	Codec Codec
 }
 
 // NewMultiCache 创建一个新的组合缓存实例
 func NewMultiCache(caches ...Cache) *MultiCache {
+	return NewMultiCacheWithOptions(MultiCacheOptions{}, caches...)
+}
+
+// NewMultiCacheWithOptions 使用指定配置创建一个新的组合缓存实例
+func NewMultiCacheWithOptions(opts MultiCacheOptions, caches ...Cache) *MultiCache {
+	if opts.Codec == nil {
+		opts.Codec = JSONCodec{}
+	}
 	return &MultiCache{
 		caches: caches,
+		codec:  opts.Codec,
 	}
 }
 
 // Set 将键值对存储到所有缓存中，并设置过期时间
 func (m *MultiCache) Set(key string, value interface{}, expiration time.Duration) error {
+	return m.SetCtx(context.Background(), key, value, expiration)
+}
+
+// SetCtx 将键值对存储到所有缓存中，并设置过期时间
+func (m *MultiCache) SetCtx(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	str, err := encodeValue(m.codec, value)
+	if err != nil {
+		return err
+	}
 	for _, cache := range m.caches {
-		err := cache.Set(key, ToString(value), expiration)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := WithContext(cache).SetCtx(ctx, key, str, expiration)
 		if err != nil {
 			// 记录错误但继续设置其他缓存
 			// 在实际应用中，可能需要更好的错误处理机制
@@ -30,26 +59,42 @@ func (m *MultiCache) Set(key string, value interface{}, expiration time.Duration
 
 // Get 从缓存中获取指定键的值，按顺序查找直到找到
 func (m *MultiCache) Get(key string) (string, error) {
+	return m.GetCtx(context.Background(), key)
+}
+
+// GetCtx 从缓存中获取指定键的值，按顺序查找直到找到
+func (m *MultiCache) GetCtx(ctx context.Context, key string) (string, error) {
 	for i, cache := range m.caches {
-		value, err := cache.Get(key)
+		value, err := WithContext(cache).GetCtx(ctx, key)
 		if err == nil {
 			// 如果在后面的缓存中找到了，在前面的缓存中设置该值（提升性能）
 			for j := 0; j < i; j++ {
-				cacheErr := m.caches[j].Set(key, value, 0) // 使用默认过期时间
+				cacheErr := WithContext(m.caches[j]).SetCtx(ctx, key, value, 0) // 使用默认过期时间
 				if cacheErr != nil {
 					// 记录错误但继续
 				}
 			}
 			return value, nil
 		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
 	}
 	return "", ErrKeyNotFound
 }
 
 // Delete 从所有缓存中删除指定键
 func (m *MultiCache) Delete(key string) error {
+	return m.DeleteCtx(context.Background(), key)
+}
+
+// DeleteCtx 从所有缓存中删除指定键
+func (m *MultiCache) DeleteCtx(ctx context.Context, key string) error {
 	for _, cache := range m.caches {
-		err := cache.Delete(key)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := WithContext(cache).DeleteCtx(ctx, key)
 		if err != nil {
 			// 记录错误但继续删除其他缓存
 		}
@@ -59,19 +104,35 @@ func (m *MultiCache) Delete(key string) error {
 
 // Exists 检查指定键是否存在于任意缓存中
 func (m *MultiCache) Exists(key string) (bool, error) {
+	return m.ExistsCtx(context.Background(), key)
+}
+
+// ExistsCtx 检查指定键是否存在于任意缓存中
+func (m *MultiCache) ExistsCtx(ctx context.Context, key string) (bool, error) {
 	for _, cache := range m.caches {
-		exists, err := cache.Exists(key)
+		exists, err := WithContext(cache).ExistsCtx(ctx, key)
 		if err == nil && exists {
 			return true, nil
 		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return false, ctxErr
+		}
 	}
 	return false, nil
 }
 
 // Expire 设置所有缓存中键的过期时间
 func (m *MultiCache) Expire(key string, expiration time.Duration) error {
+	return m.ExpireCtx(context.Background(), key, expiration)
+}
+
+// ExpireCtx 设置所有缓存中键的过期时间
+func (m *MultiCache) ExpireCtx(ctx context.Context, key string, expiration time.Duration) error {
 	for _, cache := range m.caches {
-		err := cache.Expire(key, expiration)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := WithContext(cache).ExpireCtx(ctx, key, expiration)
 		if err != nil {
 			// 记录错误但继续设置其他缓存
 		}
@@ -81,15 +142,38 @@ func (m *MultiCache) Expire(key string, expiration time.Duration) error {
 
 // TTL 获取键的剩余生存时间（从第一个找到的缓存中获取）
 func (m *MultiCache) TTL(key string) (time.Duration, error) {
+	return m.TTLCtx(context.Background(), key)
+}
+
+// TTLCtx 获取键的剩余生存时间（从第一个找到的缓存中获取）
+func (m *MultiCache) TTLCtx(ctx context.Context, key string) (time.Duration, error) {
 	for _, cache := range m.caches {
-		ttl, err := cache.TTL(key)
+		ttl, err := WithContext(cache).TTLCtx(ctx, key)
 		if err == nil {
 			return ttl, nil
 		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 0, ctxErr
+		}
 	}
 	return 0, ErrKeyNotFound
 }
 
+// Codec 返回缓存使用的序列化方式
+func (m *MultiCache) Codec() Codec {
+	return m.codec
+}
+
+// OnEvict 在每一层支持EvictObserver的缓存上注册监听函数，每一层的移除分别通知，
+// 同一个键可能收到多次回调；不支持的层（如Redis）被忽略
+func (m *MultiCache) OnEvict(fn func(key, value string, reason EvictReason)) {
+	for _, cache := range m.caches {
+		if observer, ok := cache.(EvictObserver); ok {
+			observer.OnEvict(fn)
+		}
+	}
+}
+
 // Close 关闭所有缓存连接
 func (m *MultiCache) Close() error {
 	for _, cache := range m.caches {
@@ -100,3 +184,169 @@ func (m *MultiCache) Close() error {
 	}
 	return nil
 }
+
+// GetMulti 逐层批量获取，上一层未命中的键才会查询下一层，并将下层命中的值回填到上层
+func (m *MultiCache) GetMulti(keys []string) (map[string]string, error) {
+	values := make(map[string]string, len(keys))
+	missing := keys
+	for i, cache := range m.caches {
+		if len(missing) == 0 {
+			break
+		}
+		found, _ := batchCacheOf(cache).GetMulti(missing)
+		if len(found) == 0 {
+			continue
+		}
+
+		backfill := make(map[string]interface{}, len(found))
+		for key, value := range found {
+			values[key] = value
+			backfill[key] = value
+		}
+		for j := 0; j < i; j++ {
+			// 记录错误但继续，与Get保持一致使用默认过期时间
+			_ = batchCacheOf(m.caches[j]).SetMulti(backfill, 0)
+		}
+
+		next := missing[:0:0]
+		for _, key := range missing {
+			if _, ok := found[key]; !ok {
+				next = append(next, key)
+			}
+		}
+		missing = next
+	}
+	return values, nil
+}
+
+// SetMulti 将键值对批量写入所有缓存，值只序列化一次
+func (m *MultiCache) SetMulti(items map[string]interface{}, expiration time.Duration) error {
+	errs := make(map[string]error)
+	encoded := encodeItems(m.codec, items, errs)
+
+	values := make(map[string]interface{}, len(encoded))
+	for key, value := range encoded {
+		values[key] = value
+	}
+	for _, cache := range m.caches {
+		// 记录错误但继续设置其他缓存
+		_ = batchCacheOf(cache).SetMulti(values, expiration)
+	}
+	return newBatchError(errs)
+}
+
+// DeleteMulti 从所有缓存中批量删除指定键
+func (m *MultiCache) DeleteMulti(keys []string) error {
+	for _, cache := range m.caches {
+		// 记录错误但继续删除其他缓存
+		_ = batchCacheOf(cache).DeleteMulti(keys)
+	}
+	return nil
+}
+
+// Keys 返回所有缓存中匹配pattern的键（去重），不支持键枚举的缓存会被跳过
+func (m *MultiCache) Keys(pattern string) ([]string, error) {
+	var keys []string
+	for _, cache := range m.caches {
+		sc, ok := cache.(ScanCache)
+		if !ok {
+			continue
+		}
+		tierKeys, err := sc.Keys(pattern)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, tierKeys...)
+	}
+	return uniqueKeys(keys), nil
+}
+
+// Scan 返回所有缓存中以prefix开头的键（去重）的迭代器
+func (m *MultiCache) Scan(prefix string) KeyIterator {
+	var keys []string
+	for _, cache := range m.caches {
+		sc, ok := cache.(ScanCache)
+		if !ok {
+			continue
+		}
+		tierKeys, err := collectKeys(sc.Scan(prefix))
+		if err != nil {
+			return newSliceKeyIterator(nil, err)
+		}
+		keys = append(keys, tierKeys...)
+	}
+	return newSliceKeyIterator(uniqueKeys(keys), nil)
+}
+
+// Clear 清空所有缓存，不支持清空的缓存会被跳过，返回遇到的第一个错误
+func (m *MultiCache) Clear() error {
+	var firstErr error
+	for _, cache := range m.caches {
+		cc, ok := cache.(ClearableCache)
+		if !ok {
+			continue
+		}
+		if err := cc.Clear(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// SetWithTags 将键值对写入所有缓存，支持标签的缓存同时关联标签
+func (m *MultiCache) SetWithTags(key string, value interface{}, expiration time.Duration, tags ...string) error {
+	str, err := encodeValue(m.codec, value)
+	if err != nil {
+		return err
+	}
+	for _, cache := range m.caches {
+		if tc, ok := cache.(TagCache); ok {
+			err = tc.SetWithTags(key, str, expiration, tags...)
+		} else {
+			err = cache.Set(key, str, expiration)
+		}
+		if err != nil {
+			// 记录错误但继续设置其他缓存
+		}
+	}
+	return nil
+}
+
+// InvalidateTag 在每一层缓存中使标签失效。
+// 通过Get回填到上层的键没有关联标签，因此先收集各层记录的键并从所有层删除
+func (m *MultiCache) InvalidateTag(tag string) error {
+	keys, err := m.TagKeys(tag)
+	if err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		_ = m.DeleteMulti(keys)
+	}
+
+	var firstErr error
+	for _, cache := range m.caches {
+		if tc, ok := cache.(TagCache); ok {
+			if err := tc.InvalidateTag(tag); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// TagKeys 返回所有缓存中关联了tag的键（去重）
+func (m *MultiCache) TagKeys(tag string) ([]string, error) {
+	var keys []string
+	for _, cache := range m.caches {
+		tc, ok := cache.(TagCache)
+		if !ok {
+			continue
+		}
+		tierKeys, err := tc.TagKeys(tag)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, tierKeys...)
+	}
+	return uniqueKeys(keys), nil
+}
@@ -0,0 +1,161 @@
+package go_cache
+
+import (
+	"strings"
+)
+
+// sliceKeyIterator 遍历预先收集好的键
+type sliceKeyIterator struct {
+	keys []string
+	pos  int
+	err  error
+}
+
+func newSliceKeyIterator(keys []string, err error) *sliceKeyIterator {
+	return &sliceKeyIterator{keys: keys, err: err}
+}
+
+func (it *sliceKeyIterator) Next() bool {
+	if it.err != nil || it.pos >= len(it.keys) {
+		return false
+	}
+	it.pos++
+	return true
+}
+
+func (it *sliceKeyIterator) Key() string {
+	if it.pos == 0 || it.pos > len(it.keys) {
+		return ""
+	}
+	return it.keys[it.pos-1]
+}
+
+func (it *sliceKeyIterator) Err() error {
+	return it.err
+}
+
+// collectKeys 读取迭代器中的所有键
+func collectKeys(iter KeyIterator) ([]string, error) {
+	var keys []string
+	for iter.Next() {
+		keys = append(keys, iter.Key())
+	}
+	return keys, iter.Err()
+}
+
+// uniqueKeys 去掉重复的键，保持原有顺序
+func uniqueKeys(keys []string) []string {
+	seen := make(map[string]struct{}, len(keys))
+	result := keys[:0]
+	for _, key := range keys {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, key)
+	}
+	return result
+}
+
+// escapePattern 转义glob中的特殊字符，使s按字面匹配
+func escapePattern(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// matchPattern 按Redis的glob规则匹配，*可以匹配包括/在内的任意字符。
+// 除*之外的元素都恰好匹配一个字符，因此只需记住最近一个*的位置：后面匹配失败时让它多匹配一个字符再继续，
+// 不会像递归回溯那样随*的个数指数增长
+func matchPattern(pattern, s string) bool {
+	p, i := 0, 0
+	starP, starI := -1, 0
+	for i < len(s) {
+		if p < len(pattern) && pattern[p] == '*' {
+			for p < len(pattern) && pattern[p] == '*' {
+				p++
+			}
+			if p == len(pattern) {
+				return true
+			}
+			starP, starI = p, i
+			continue
+		}
+		if next, ok := matchElem(pattern, p, s[i]); ok {
+			p, i = next, i+1
+			continue
+		}
+		if starP < 0 {
+			return false
+		}
+		starI++
+		p, i = starP, starI
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// matchElem 用pattern中从p开始的一个元素匹配字符c，返回下一个元素的位置
+func matchElem(pattern string, p int, c byte) (int, bool) {
+	if p >= len(pattern) {
+		return p, false
+	}
+	switch pattern[p] {
+	case '?':
+		return p + 1, true
+	case '[':
+		end := strings.IndexByte(pattern[p+1:], ']')
+		if end < 0 {
+			// 没有闭合的]按字面匹配
+			return p + 1, c == '['
+		}
+		return p + end + 2, matchClass(pattern[p+1:p+1+end], c)
+	case '\\':
+		if p+1 < len(pattern) {
+			p++
+		}
+	}
+	return p + 1, pattern[p] == c
+}
+
+// matchClass 匹配[...]中的字符集合，支持^取反和a-z范围
+func matchClass(class string, c byte) bool {
+	negate := false
+	if len(class) > 0 && class[0] == '^' {
+		negate = true
+		class = class[1:]
+	}
+	matched := false
+	for i := 0; i < len(class); i++ {
+		if class[i] == '\\' && i+1 < len(class) {
+			i++
+			if class[i] == c {
+				matched = true
+			}
+			continue
+		}
+		if i+2 < len(class) && class[i+1] == '-' {
+			lo, hi := class[i], class[i+2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			if c >= lo && c <= hi {
+				matched = true
+			}
+			i += 2
+			continue
+		}
+		if class[i] == c {
+			matched = true
+		}
+	}
+	return matched != negate
+}
@@ -0,0 +1,129 @@
+package go_cache
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchPattern(t *testing.T) {
+	cases := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "", true},
+		{"*", "a/b:c", true},
+		{"user:*", "user:1", true},
+		{"user:*", "order:1", false},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{`h\*llo`, "h*llo", true},
+		{`h\*llo`, "hello", false},
+		{"*:*:end", "a:b:c:end", true},
+		{"*:*:end", "a:b:c:en", false},
+		{"a*b*c", "abxbxc", true},
+		{"*?", "", false},
+		{"h[", "h[", true},
+		{`h\`, `h\`, true},
+	}
+	for _, c := range cases {
+		if got := matchPattern(c.pattern, c.s); got != c.want {
+			t.Errorf("matchPattern(%q, %q) 期望 %v, 实际 %v", c.pattern, c.s, c.want, got)
+		}
+	}
+	if !matchPattern(escapePattern("a*[b]")+"*", "a*[b]c") {
+		t.Error("期望转义后的前缀按字面匹配")
+	}
+
+	// 多个*不会导致指数级回溯
+	long := strings.Repeat("a", 10000)
+	if matchPattern("a*a*a*a*a*a*a*a*a*a*b", long) {
+		t.Error("期望不以b结尾的键不匹配")
+	}
+	if !matchPattern("a*a*a*a*a*a*a*a*a*a*a", long) {
+		t.Error("期望全部由a组成的键匹配")
+	}
+}
+
+func TestScanCache(t *testing.T) {
+	defer Init()()
+	fileCache, err := NewFileCache(testFilePath)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	caches := map[string]ScanCache{
+		"redis":   redisServer,
+		"memory":  NewMemoryCache(),
+		"arena":   newArenaTestCache(),
+		"file":    fileCache,
+		"disklog": newDiskLogTestCache(t, nil),
+		"multi":   NewMultiCache(NewMemoryCache(), fileCache),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			c := cache.(Cache)
+			for _, key := range []string{"scan:user:1", "scan:user:2", "scan:order:1"} {
+				if err := c.Set(key, "v", time.Minute); err != nil {
+					t.Fatalf("设置键值对失败: %v", err)
+				}
+				defer c.Delete(key)
+			}
+			_ = c.Set("scan:user:expired", "v", time.Millisecond)
+			defer c.Delete("scan:user:expired")
+			time.Sleep(10 * time.Millisecond)
+
+			keys, err := cache.Keys("scan:user:*")
+			if err != nil {
+				t.Fatalf("获取键失败: %v", err)
+			}
+			sort.Strings(keys)
+			if len(keys) != 2 || keys[0] != "scan:user:1" || keys[1] != "scan:user:2" {
+				t.Errorf("期望 [scan:user:1 scan:user:2], 实际 %v", keys)
+			}
+
+			iter := cache.Scan("scan:")
+			var scanned []string
+			for iter.Next() {
+				scanned = append(scanned, iter.Key())
+			}
+			if err := iter.Err(); err != nil {
+				t.Fatalf("遍历键失败: %v", err)
+			}
+			if len(uniqueKeys(scanned)) != 3 {
+				t.Errorf("期望遍历到3个键, 实际 %v", scanned)
+			}
+		})
+	}
+}
+
+func TestFileCache_ScanSkipsForeignFiles(t *testing.T) {
+	defer Init()()
+	cache, _ := NewFileCache(testFilePath)
+	_ = cache.Set("k1", "v1", 0)
+
+	// 旧版本写入的没有原始键的文件、损坏文件和无关文件都会被忽略
+	_ = os.WriteFile(filepath.Join(testFilePath, "readme.txt"), []byte("x"), 0644)
+	legacy := cache.getFilePath("legacy")
+	_ = os.MkdirAll(filepath.Dir(legacy), 0755)
+	_ = os.WriteFile(legacy, []byte(`{"value":"v","expiration":"0001-01-01T00:00:00Z"}`), 0644)
+	broken := cache.getFilePath("broken")
+	_ = os.MkdirAll(filepath.Dir(broken), 0755)
+	_ = os.WriteFile(broken, []byte(`{"value":`), 0644)
+
+	keys, err := cache.Keys("*")
+	if err != nil {
+		t.Fatalf("获取键失败: %v", err)
+	}
+	if len(keys) != 1 || keys[0] != "k1" {
+		t.Errorf("期望 [k1], 实际 %v", keys)
+	}
+}
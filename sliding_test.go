@@ -0,0 +1,209 @@
+package go_cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlidingCache(t *testing.T) {
+	defer Init()()
+	clock := NewFakeClock(time.Now())
+	fileCache, err := NewFileCacheWithOptions(testFilePath, FileCacheOptions{Clock: clock})
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	caches := map[string]Cache{
+		"memory":  NewMemoryCacheWithOptions(MemoryCacheOptions{Clock: clock}),
+		"arena":   NewMemoryCacheWithOptions(MemoryCacheOptions{Storage: ArenaStorage, MaxBytes: 1 << 20, Clock: clock}),
+		"file":    fileCache,
+		"disklog": newDiskLogTestCache(t, clock),
+		"binary":  newBinaryFileTestCache(t, clock),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			defer cache.Close()
+			sliding := cache.(SlidingCache)
+
+			_ = sliding.SetSliding("s", "v", time.Minute)
+			_ = cache.Set("plain", "v", time.Minute)
+			for i := 0; i < 3; i++ {
+				clock.Advance(40 * time.Second)
+				if got, err := cache.Get("s"); got != "v" {
+					t.Fatalf("期望读取时续期, 实际 %q %v", got, err)
+				}
+			}
+			if _, err := cache.Get("plain"); err != ErrKeyNotFound {
+				t.Errorf("期望普通的键按写入时的过期时间过期, 实际 %v", err)
+			}
+
+			// GetMulti同样续期，Exists不续期
+			clock.Advance(40 * time.Second)
+			if values, _ := cache.(BatchCache).GetMulti([]string{"s"}); values["s"] != "v" {
+				t.Fatalf("期望批量读取命中, 实际 %v", values)
+			}
+			clock.Advance(40 * time.Second)
+			if exists, _ := cache.Exists("s"); !exists {
+				t.Fatal("期望GetMulti续期后键仍然存在")
+			}
+			clock.Advance(30 * time.Second)
+			if exists, _ := cache.Exists("s"); exists {
+				t.Error("期望Exists不续期")
+			}
+
+			// 再次用Set写入后不再滑动
+			_ = sliding.SetSliding("s", "v", time.Minute)
+			_ = cache.Set("s", "v2", time.Minute)
+			clock.Advance(40 * time.Second)
+			_, _ = cache.Get("s")
+			clock.Advance(30 * time.Second)
+			if _, err := cache.Get("s"); err != ErrKeyNotFound {
+				t.Errorf("期望覆盖写入后不再滑动, 实际 %v", err)
+			}
+		})
+	}
+}
+
+func TestSlidingCache_GetAndTouch(t *testing.T) {
+	defer Init()()
+	clock := NewFakeClock(time.Now())
+	fileCache, err := NewFileCacheWithOptions(testFilePath, FileCacheOptions{Clock: clock})
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	caches := map[string]Cache{
+		"memory":  NewMemoryCacheWithOptions(MemoryCacheOptions{Clock: clock}),
+		"arena":   NewMemoryCacheWithOptions(MemoryCacheOptions{Storage: ArenaStorage, MaxBytes: 1 << 20, Clock: clock}),
+		"file":    fileCache,
+		"disklog": newDiskLogTestCache(t, clock),
+		"binary":  newBinaryFileTestCache(t, clock),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			defer cache.Close()
+			sliding := cache.(SlidingCache)
+
+			if _, err := sliding.GetAndTouch("missing", time.Hour); err != ErrKeyNotFound {
+				t.Errorf("期望不存在的键返回ErrKeyNotFound, 实际 %v", err)
+			}
+
+			_ = cache.Set("k", "v", time.Minute)
+			if got, err := sliding.GetAndTouch("k", time.Hour); got != "v" || err != nil {
+				t.Fatalf("期望GetAndTouch返回v, 实际 %q %v", got, err)
+			}
+			if ttl, _ := cache.TTL("k"); ttl != time.Hour {
+				t.Errorf("期望过期时间重置为1小时, 实际 %v", ttl)
+			}
+			if got, _ := sliding.GetAndTouch("k", time.Second); got != "v" {
+				t.Fatalf("期望GetAndTouch返回v, 实际 %q", got)
+			}
+			if ttl, _ := cache.TTL("k"); ttl != time.Second {
+				t.Errorf("期望过期时间可以缩短, 实际 %v", ttl)
+			}
+			_, _ = sliding.GetAndTouch("k", 0)
+			if ttl, _ := cache.TTL("k"); ttl != -1 {
+				t.Errorf("期望ttl为0时永不过期, 实际 %v", ttl)
+			}
+		})
+	}
+}
+
+func TestFileCache_SlidingThrottle(t *testing.T) {
+	defer Init()()
+	clock := NewFakeClock(time.Now())
+	cache, err := NewFileCacheWithOptions(testFilePath, FileCacheOptions{Clock: clock})
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+
+	_ = cache.SetSliding("k", "v", time.Minute)
+	// 续期不超过ttl/10时不重写文件
+	clock.Advance(time.Second)
+	_, _ = cache.Get("k")
+	if ttl, _ := cache.TTL("k"); ttl != 59*time.Second {
+		t.Errorf("期望小幅续期不写回, 实际剩余 %v", ttl)
+	}
+	clock.Advance(10 * time.Second)
+	_, _ = cache.Get("k")
+	if ttl, _ := cache.TTL("k"); ttl != time.Minute {
+		t.Errorf("期望续期超过ttl/10时写回, 实际剩余 %v", ttl)
+	}
+}
+
+func TestRedisCache_Sliding(t *testing.T) {
+	cache := redisServer
+	defer cache.Delete("sliding")
+	defer cache.Delete("plain")
+
+	_ = cache.SetSliding("sliding", "v", time.Second)
+	_ = cache.Set("plain", "p", 0)
+	for i := 0; i < 3; i++ {
+		time.Sleep(600 * time.Millisecond)
+		if got, err := cache.Get("sliding"); got != "v" {
+			t.Fatalf("期望读取时续期, 实际 %q %v", got, err)
+		}
+	}
+	values, err := cache.GetMulti([]string{"sliding", "plain", "missing"})
+	if err != nil || len(values) != 2 || values["sliding"] != "v" || values["plain"] != "p" {
+		t.Errorf("期望批量读取 map[plain:p sliding:v], 实际 %v %v", values, err)
+	}
+
+	if got, _ := cache.GetAndTouch("plain", time.Hour); got != "p" {
+		t.Errorf("期望GetAndTouch返回p, 实际 %q", got)
+	}
+	if ttl, _ := cache.TTL("plain"); ttl <= time.Minute {
+		t.Errorf("期望过期时间重置为1小时, 实际 %v", ttl)
+	}
+	if _, err := cache.GetAndTouch("missing", time.Hour); err != ErrKeyNotFound {
+		t.Errorf("期望不存在的键返回ErrKeyNotFound, 实际 %v", err)
+	}
+
+	// CompareAndSwap和计数器作用于去掉头部后的值
+	if ok, err := cache.CompareAndSwap("sliding", "v", "w", time.Second); !ok || err != nil {
+		t.Errorf("期望CompareAndSwap成功, 实际 %v %v", ok, err)
+	}
+	defer cache.Delete("counter")
+	_ = cache.SetSliding("counter", "1", time.Second)
+	if n, err := cache.Incr("counter", 0); n != 2 || err != nil {
+		t.Errorf("期望计数器为2, 实际 %d %v", n, err)
+	}
+	time.Sleep(600 * time.Millisecond)
+	if got, _ := cache.Get("counter"); got != "2" {
+		t.Errorf("期望读取计数器2, 实际 %q", got)
+	}
+	if ttl, _ := cache.TTL("counter"); ttl <= 500*time.Millisecond {
+		t.Errorf("期望计数器仍然滑动过期, 实际 %v", ttl)
+	}
+
+	// 任意内容的值都原样返回，Set之后不再滑动
+	forged := redisSlidingMark + "5000:x"
+	_ = cache.Set("plain", forged, time.Second)
+	time.Sleep(600 * time.Millisecond)
+	if got, _ := cache.Get("plain"); got != forged {
+		t.Errorf("期望值原样返回, 实际 %q", got)
+	}
+	if ttl, _ := cache.TTL("plain"); ttl > 500*time.Millisecond {
+		t.Errorf("期望普通值不续期, 实际 %v", ttl)
+	}
+	_ = cache.Set("counter", "1", time.Second)
+	time.Sleep(600 * time.Millisecond)
+	_, _ = cache.Get("counter")
+	if ttl, _ := cache.TTL("counter"); ttl > 500*time.Millisecond {
+		t.Errorf("期望Set之后不再续期, 实际 %v", ttl)
+	}
+}
+
+func TestRedisValueEncoding(t *testing.T) {
+	for _, value := range []string{"", "v", redisFlag, redisEscapeMark + "x", slidingHeader(time.Second) + "x"} {
+		if got, sliding := decodeRedisValue(escapeRedisValue(value)); got != value || sliding != 0 {
+			t.Errorf("期望普通值 %q 原样返回, 实际 %q %v", value, got, sliding)
+		}
+	}
+	if escapeRedisValue("v") != "v" {
+		t.Error("期望不以标记开头的值不转义")
+	}
+	if got, sliding := decodeRedisValue(slidingHeader(time.Minute) + redisFlag + "v"); got != redisFlag+"v" || sliding != time.Minute {
+		t.Errorf("期望滑动过期的值去掉头部, 实际 %q %v", got, sliding)
+	}
+}
@@ -0,0 +1,141 @@
+package go_cache
+
+import (
+	"os"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestTagCache(t *testing.T) {
+	defer Init()()
+	fileCache, err := NewFileCache(testFilePath)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	caches := map[string]TagCache{
+		"redis":   redisServer,
+		"memory":  NewMemoryCache(),
+		"arena":   newArenaTestCache(),
+		"file":    fileCache,
+		"disklog": newDiskLogTestCache(t, nil),
+		"binary":  newBinaryFileTestCache(t, nil),
+		"multi":   NewMultiCache(NewMemoryCache(), fileCache),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			c := cache.(Cache)
+			defer c.Delete("product:1:page")
+			defer c.Delete("product:1:list")
+			defer c.Delete("product:2:page")
+			defer c.Delete("product:1:replaced")
+
+			_ = cache.SetWithTags("product:1:page", "p1", time.Minute, "product:1", "pages")
+			_ = cache.SetWithTags("product:1:list", "l1", time.Minute, "product:1")
+			_ = cache.SetWithTags("product:2:page", "p2", time.Minute, "product:2", "pages")
+
+			keys, err := cache.TagKeys("pages")
+			if err != nil {
+				t.Fatalf("获取标签键失败: %v", err)
+			}
+			sort.Strings(keys)
+			if len(keys) != 2 || keys[0] != "product:1:page" || keys[1] != "product:2:page" {
+				t.Errorf("期望 [product:1:page product:2:page], 实际 %v", keys)
+			}
+
+			if err := cache.InvalidateTag("product:1"); err != nil {
+				t.Fatalf("标签失效失败: %v", err)
+			}
+			for _, key := range []string{"product:1:page", "product:1:list"} {
+				if exists, _ := c.Exists(key); exists {
+					t.Errorf("期望键 %s 已失效", key)
+				}
+			}
+			if got, _ := c.Get("product:2:page"); got != "p2" {
+				t.Errorf("期望其他标签的键不受影响, 实际: %s", got)
+			}
+
+			// 失效不存在的标签不报错
+			if err := cache.InvalidateTag("no_such_tag"); err != nil {
+				t.Errorf("失效不存在的标签失败: %v", err)
+			}
+		})
+	}
+}
+
+func TestTagCache_RetaggedKeySurvives(t *testing.T) {
+	defer Init()()
+	fileCache, _ := NewFileCache(testFilePath)
+	for name, cache := range map[string]TagCache{"memory": NewMemoryCache(), "file": fileCache} {
+		t.Run(name, func(t *testing.T) {
+			c := cache.(Cache)
+			_ = cache.SetWithTags("k", "v1", 0, "old")
+			// 重新写入后不再关联old标签
+			_ = c.Set("k", "v2", 0)
+			_ = cache.InvalidateTag("old")
+			if got, _ := c.Get("k"); got != "v2" {
+				t.Errorf("期望重新写入的键不被旧标签删除, 实际: %s", got)
+			}
+		})
+	}
+}
+
+func TestMultiCache_InvalidateBackfilledKey(t *testing.T) {
+	defer Init()()
+	memoryCache := NewMemoryCache()
+	fileCache, _ := NewFileCache(testFilePath)
+	cache := NewMultiCache(memoryCache, fileCache)
+	defer cache.Close()
+
+	_ = fileCache.SetWithTags("k", "v", 0, "t")
+	// Get将值回填到内存缓存，回填时没有标签
+	if got, _ := cache.Get("k"); got != "v" {
+		t.Fatalf("获取键值对失败: %s", got)
+	}
+	_ = cache.InvalidateTag("t")
+	if exists, _ := memoryCache.Exists("k"); exists {
+		t.Error("期望回填到上层的键同样失效")
+	}
+}
+
+func TestFileCache_TagIndexPruned(t *testing.T) {
+	defer Init()()
+	clock := NewFakeClock(time.Now())
+	cache, err := NewFileCacheWithOptions(testFilePath, FileCacheOptions{Clock: clock})
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	indexKeys := func() []string {
+		index, _ := cache.readTagIndex(cache.getTagPath("t"))
+		keys := append([]string(nil), index.Keys...)
+		sort.Strings(keys)
+		return keys
+	}
+
+	for _, key := range []string{"deleted", "overwritten", "retagged", "cas", "expired", "kept"} {
+		_ = cache.SetWithTags(key, "v", time.Hour, "t")
+	}
+	_ = cache.Expire("expired", time.Second)
+	_ = cache.Delete("deleted")
+	_ = cache.Set("overwritten", "v2", 0)
+	_ = cache.SetWithTags("retagged", "v2", 0, "other")
+	_, _ = cache.CompareAndSwap("cas", "v", "v2", 0)
+	// 重新写入时仍带有同一标签，不从索引中删除
+	_ = cache.SetWithTags("kept", "v2", 0, "t")
+	if got := indexKeys(); len(got) != 2 || got[0] != "expired" || got[1] != "kept" {
+		t.Errorf("期望删除和覆盖时清理索引, 实际 %v", got)
+	}
+
+	clock.Advance(time.Minute)
+	if err := cache.Sweep(); err != nil {
+		t.Fatalf("清理失败: %v", err)
+	}
+	if got := indexKeys(); len(got) != 1 || got[0] != "kept" {
+		t.Errorf("期望Sweep清理过期的键, 实际 %v", got)
+	}
+	_ = cache.InvalidateTag("other")
+	if _, err := os.Stat(cache.getTagPath("other")); !os.IsNotExist(err) {
+		t.Errorf("期望空的索引文件被删除, 实际 %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package go_cache
+
+import (
+	"time"
+)
+
+// TypedCache 是基于任意Cache的泛型缓存包装，直接存取T类型的值
+type TypedCache[T any] struct {
+	cache Cache
+	codec Codec
+}
+
+// NewTypedCache 创建一个新的泛型缓存实例，codec为nil时使用底层缓存配置的Codec
+func NewTypedCache[T any](cache Cache, codec Codec) *TypedCache[T] {
+	if codec == nil {
+		codec = codecOf(cache)
+	}
+	return &TypedCache[T]{
+		cache: cache,
+		codec: codec,
+	}
+}
+
+// Set 将值序列化后存储到缓存中，并设置过期时间
+func (t *TypedCache[T]) Set(key string, value T, expiration time.Duration) error {
+	data, err := t.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return t.cache.Set(key, string(data), expiration)
+}
+
+// Get 从缓存中获取指定键的值并反序列化为T
+func (t *TypedCache[T]) Get(key string) (T, error) {
+	var value T
+	data, err := t.cache.Get(key)
+	if err != nil {
+		return value, err
+	}
+	err = t.codec.Unmarshal([]byte(data), &value)
+	return value, err
+}
+
+// Delete 从缓存中删除指定键
+func (t *TypedCache[T]) Delete(key string) error {
+	return t.cache.Delete(key)
+}
+
+// Exists 检查指定键是否存在于缓存中
+func (t *TypedCache[T]) Exists(key string) (bool, error) {
+	return t.cache.Exists(key)
+}
+
+// Expire 设置键的过期时间
+func (t *TypedCache[T]) Expire(key string, expiration time.Duration) error {
+	return t.cache.Expire(key, expiration)
+}
+
+// TTL 获取键的剩余生存时间
+func (t *TypedCache[T]) TTL(key string) (time.Duration, error) {
+	return t.cache.TTL(key)
+}
+
+// Cache 返回底层缓存
+func (t *TypedCache[T]) Cache() Cache {
+	return t.cache
+}
@@ -0,0 +1,93 @@
+package go_cache
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type typedTestUser struct {
+	Name  string
+	Age   int
+	Tags  []string
+	Extra map[string]float64
+}
+
+// typedTestCaches 返回参与泛型缓存测试的所有缓存后端
+func typedTestCaches(t *testing.T) map[string]Cache {
+	fileCache, err := NewFileCache(testFilePath)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	return map[string]Cache{
+		"redis":   redisServer,
+		"memory":  NewMemoryCache(),
+		"arena":   newArenaTestCache(),
+		"file":    fileCache,
+		"disklog": newDiskLogTestCache(t, nil),
+		"binary":  newBinaryFileTestCache(t, nil),
+	}
+}
+
+func testTypedRoundTrip[T any](t *testing.T, cache Cache, codec Codec, key string, value T) {
+	t.Helper()
+	tc := NewTypedCache[T](cache, codec)
+	if err := tc.Set(key, value, 5*time.Second); err != nil {
+		t.Fatalf("设置键值对失败: %v", err)
+	}
+	got, err := tc.Get(key)
+	if err != nil {
+		t.Fatalf("获取键值对失败: %v", err)
+	}
+	if !reflect.DeepEqual(got, value) {
+		t.Errorf("期望值 %#v, 实际值 %#v", value, got)
+	}
+	if err := tc.Delete(key); err != nil {
+		t.Fatalf("删除键失败: %v", err)
+	}
+}
+
+func TestTypedCache_RoundTrip(t *testing.T) {
+	defer Init()()
+	for name, cache := range typedTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			testTypedRoundTrip(t, cache, nil, "typed_struct", typedTestUser{
+				Name:  "张三",
+				Age:   18,
+				Tags:  []string{"a", "b"},
+				Extra: map[string]float64{"score": 99.5},
+			})
+			testTypedRoundTrip(t, cache, nil, "typed_ptr", &typedTestUser{Name: "t1"})
+			testTypedRoundTrip(t, cache, nil, "typed_slice", []int{1, 2, 3})
+			testTypedRoundTrip(t, cache, nil, "typed_map", map[string]string{"k1": "v1", "k2": "v2"})
+			testTypedRoundTrip(t, cache, nil, "typed_int", int64(-42))
+			testTypedRoundTrip(t, cache, nil, "typed_float", 3.1415)
+			testTypedRoundTrip(t, cache, nil, "typed_string", "hello")
+			testTypedRoundTrip(t, cache, nil, "typed_bytes", []byte{0, 1, 2, 0xff})
+		})
+	}
+}
+
+func TestTypedCache_Get(t *testing.T) {
+	cache := NewMemoryCache()
+	defer cache.Close()
+	tc := NewTypedCache[[]byte](cache, nil)
+
+	if _, err := tc.Get("no_key"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("期望返回ErrKeyNotFound, 实际: %v", err)
+	}
+
+	_ = tc.Set("bytes", []byte("abc"), 0)
+	got, err := tc.Get("bytes")
+	if err != nil || !bytes.Equal(got, []byte("abc")) {
+		t.Errorf("获取键值对失败: %v %v", err, got)
+	}
+
+	// 非法数据应返回反序列化错误
+	_ = cache.Set("bad", "not json", 0)
+	if _, err := NewTypedCache[int](cache, nil).Get("bad"); err == nil {
+		t.Error("期望反序列化失败")
+	}
+}